@@ -1,19 +1,20 @@
 package args
 
 import (
-	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
-	"github.com/BurntSushi/toml"
-	"gopkg.in/yaml.v2"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 /*
@@ -25,101 +26,400 @@ import (
 var ErrHelp = errors.New("flag: help requested")
 var ErrCmdParse = errors.New("参数解析错误")
 var ErrFileNotFound = errors.New("文件未找到")
+var ErrFileArgMissing = errors.New("flag required but not provided")
 var ErrFileRead = errors.New("文件读取失败")
 var ErrFileParse = errors.New("文件解析错误")
-var ErrFileType = errors.New("文件类型不支持。仅支持：【json/yml/yaml/toml/ini】")
+var ErrFileType error = fileTypeError{}
 var ErrArgType = errors.New("不支持的参数类型")
+var ErrArgAmbiguous = errors.New("参数缩写不明确")
+var ErrEnvConflict = errors.New("环境变量名冲突")
 
 // AppArgs 参数解析应用类型
 type AppArgs struct {
-	Name           string
-	Version        string
-	Usage          string
-	CfgData        interface{}
-	CfgFilePath    string
-	CfgFileCmdArg  string
-	CfgFileUsage   string
-	CfgFileRequire bool
-	EnvPrefix      string
-	HelpHandler    func() error
-	output         io.Writer
-}
-
-// Run 运行参数解析
+	Name              string
+	Version           string
+	Usage             string
+	CfgData           interface{}
+	CfgFilePath       string
+	CfgFileCmdArg     string
+	CfgFileUsage      string
+	CfgFileRequire    bool
+	CfgStrict         bool
+	CfgIncludeKey     string
+	CfgProfileArg     string
+	CfgProfileNames   []string
+	CfgSearchPaths    []string
+	CfgSearchMode     SearchMode
+	CfgProjectName    string
+	CfgProjectMerge   bool
+	CfgProjectMarkers []string
+	WorkDir           string
+	EnvPrefix         string
+	EnvSeparator      string
+	EnvElements       ElementPolicy
+	SliceMerge        SlicePolicy
+	DotEnvPaths       []string
+	EnvExpand         ExpandScope
+	ArgAbbrev         bool
+	Lenient           bool
+	HelpHandler       func() error
+	output            io.Writer
+	input             io.Reader
+	stdin             []byte
+	stdinRead         bool
+	envLookup         func(name string) (string, bool)
+	envKeys           func() []string
+	dotEnv            map[string]string
+	cfgFiles          []string
+	keySources        []keySource
+	profiles          []string
+	arguments         []string
+	defaults          interface{}
+	current           atomic.Value
+	mu                sync.Mutex
+	reloadMu          sync.Mutex
+	watchInterval     time.Duration
+	onChange          []func(old, new interface{})
+	validators        []func(cfg interface{}) error
+	onReloadError     func(err error)
+	watching          bool
+	reloadSignals     []os.Signal
+	signaling         bool
+	wg                sync.WaitGroup
+	stop              chan struct{}
+	stopOnce          sync.Once
+}
+
+// Run 运行参数解析，结果写入 CfgData 中原有的对象，成功时将其深拷贝作为当前配置发布，
+// 因此 Current 返回的对象不会被之后的 Run 或重新加载修改。
+// 每次 Run 都以此时的 CfgData 作为重新加载的默认值
 func (a *AppArgs) Run(arguments []string) error {
-	flags := Bean2Args(a.CfgData)
+	a.reloadMu.Lock()
+	a.mu.Lock()
+	a.arguments = arguments
+	a.defaults = deepCopy(a.CfgData)
+	err := a.resolve(a.CfgData, arguments)
+	a.mu.Unlock()
+	if err == flag.ErrHelp {
+		a.reloadMu.Unlock()
+		return a.HelpHandler()
+	}
+	if err == nil {
+		err = a.validate(a.CfgData)
+	}
+	if err == nil {
+		a.current.Store(deepCopy(a.CfgData))
+	}
+	a.reloadMu.Unlock()
+	if err != nil {
+		return err
+	}
+	a.startWatch()
+	a.startSignalReload()
+	return nil
+}
+
+// resolve 依次解析配置文件、环境变量、命令行参数并写入 cfg，需要显示帮助时返回 flag.ErrHelp
+func (a *AppArgs) resolve(cfg interface{}, arguments []string) error {
+	flags := Bean2Args(cfg)
+	if err := a.checkEnvNames(flags); err != nil {
+		return err
+	}
 	set := a.flagSet(a.Name, flags)
 	if a.CfgFileCmdArg != "" {
-		set.String(a.CfgFileCmdArg, a.CfgFilePath, a.CfgFileUsage)
+		set.Var(newPathsValue(a.CfgFilePath), a.CfgFileCmdArg, a.CfgFileUsage)
+		set.String(a.CfgFileCmdArg+"-format", "", "配置文件格式："+strings.Join(formatNames(), "/")+"，默认根据扩展名或文件内容判断")
+	}
+	if a.CfgProfileArg != "" {
+		set.String(a.CfgProfileArg, "", "激活的配置 profile，多个以逗号分隔，如 prod,eu")
 	}
 
-	if err := set.Parse(arguments[1:]); err != nil {
+	arguments = arguments[1:]
+	if a.ArgAbbrev {
+		expanded, err := abbrevArgs(set, arguments)
+		if err != nil {
+			return err
+		}
+		arguments = expanded
+	}
+
+	if err := set.Parse(arguments); err != nil {
 		if err == flag.ErrHelp {
 			if a.HelpHandler != nil {
-				return a.HelpHandler()
+				return err
 			}
 		} else {
-			fmt.Printf("参数解析错误: %v\n", err)
-			return ErrCmdParse
+			return fmt.Errorf("%w: %v", ErrCmdParse, err)
 		}
 	}
 
+	// 加载 .env 文件，作为环境变量的补充
+	dotEnv, err := loadDotEnv(a.DotEnvPaths)
+	if err != nil {
+		return err
+	}
+	a.dotEnv = dotEnv
+	a.profiles = a.activeProfiles(set)
+
 	// 处理 配置文件 参数
-	err := a.parseFileArg(set)
-	if err != nil && a.CfgFileRequire {
+	var errs MultiError
+	err = a.parseFileArg(set, cfg)
+	if errors.Is(err, ErrArgValue) {
+		errs = a.collectErrors(set, errs, err)
+	} else if err != nil && (a.CfgFileRequire || errors.Is(err, ErrEnvExpand) || errors.Is(err, ErrUnknownKey) || errors.Is(err, ErrInclude)) {
 		return err
+	} else if err != nil && !errors.Is(err, ErrFileNotFound) && !errors.Is(err, ErrFileArgMissing) {
+		// 非必须的配置文件出错时忽略该文件，但仍输出错误信息；未指定或不存在的文件不输出
+		_, _ = fmt.Fprintf(set.Output(), "配置文件加载失败：%v\n", err)
 	}
 	// 处理 环境变量 参数
-	a.parseEnvArg(set, flags)
+	errs = a.collectErrors(set, errs, a.parseEnvArg(set, flags))
+	errs = a.collectErrors(set, errs, a.parseEnvCollectionArg(cfg))
 	// 处理 命令行   参数
-	a.parseCmdArg(set, flags)
+	errs = a.collectErrors(set, errs, a.parseCmdArg(set, flags))
 
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
-// parseFileArg 解析配置文件参数
-func (a *AppArgs) parseFileArg(set *flag.FlagSet) error {
+// collectErrors 收集参数值错误，宽松模式下仅输出错误信息
+func (a *AppArgs) collectErrors(set *flag.FlagSet, errs MultiError, err error) MultiError {
+	if !a.Lenient {
+		return appendError(errs, err)
+	}
+	for _, e := range appendError(nil, err) {
+		_, _ = fmt.Fprintln(set.Output(), e)
+	}
+	return errs
+}
+
+// parseFileArg 解析配置文件参数，多个配置文件按顺序深度合并，后面的文件优先
+func (a *AppArgs) parseFileArg(set *flag.FlagSet, cfg interface{}) error {
+	a.cfgFiles = nil
+	a.keySources = nil
+	paths := a.configPaths(set)
+	if len(paths) == 0 {
+		if a.CfgFileCmdArg == "" {
+			return nil
+		}
+		return fmt.Errorf("%w: -%s", ErrFileArgMissing, a.CfgFileCmdArg)
+	}
+	a.CfgFilePath = strings.Join(paths, ",")
+
+	files, err := a.expandConfigDirs(set, paths)
+	if err != nil {
+		return err
+	}
+	a.cfgFiles = files
+
+	if len(a.profiles) > 0 {
+		_, _ = fmt.Fprintf(set.Output(), "激活 profile %s\n", strings.Join(a.profiles, ","))
+	}
+	format := a.configFormat(set)
+	tree := map[string]interface{}{}
+	for _, filePath := range files {
+		if isProfileOverlay(files, filePath, a.profiles) {
+			continue
+		}
+		fileTree, err := a.loadWithOverlays(set, filePath, format, nil)
+		if err != nil {
+			return err
+		}
+		mergeTree(tree, fileTree, a.SliceMerge)
+	}
+
+	var errs MultiError
+	for _, err := range appendError(nil, assignTree(reflect.ValueOf(cfg), tree, "")) {
+		errs = append(errs, &FileError{Kind: ErrFileParse, Path: a.valueSource(err), Cause: err})
+	}
+	return errs.errorOrNil()
+}
+
+// configPaths 需要加载的配置文件路径：命令行指定 > 环境变量指定 > 自动发现的文件 > 默认路径。
+// 自动发现的文件中，向上查找到的项目配置优先于搜索路径中的文件
+func (a *AppArgs) configPaths(set *flag.FlagSet) []string {
+	var paths []string
+	if a.CfgFileCmdArg != "" {
+		value := set.Lookup(a.CfgFileCmdArg).Value.(*pathsValue)
+		if value.set {
+			return value.paths
+		}
+		if envValue, found := a.lookupEnv(a.getEnvName(a.CfgFileCmdArg)); found {
+			envPaths := &pathsValue{}
+			_ = envPaths.Set(envValue)
+			return envPaths.paths
+		}
+		paths = value.paths
+	}
+	found := append(a.searchConfigFiles(), a.projectConfigFiles()...)
+	if len(found) > 0 {
+		return found
+	}
+	return paths
+}
+
+// configFormat 命令行或环境变量指定的配置文件格式
+func (a *AppArgs) configFormat(set *flag.FlagSet) string {
 	if a.CfgFileCmdArg == "" {
-		return nil
+		return ""
 	}
-	cfgFlag := set.Lookup(a.CfgFileCmdArg)
-	if cfgFlag.Value.String() != "" {
-		a.CfgFilePath = cfgFlag.Value.String()
+	name := a.CfgFileCmdArg + "-format"
+	format := set.Lookup(name).Value.String()
+	if format == "" {
+		format, _ = a.lookupEnv(a.getEnvName(name))
 	}
-	a.CfgFilePath = strings.TrimSpace(a.CfgFilePath)
-	if a.CfgFilePath == "" {
-		return errors.New("flag required but not provided: -" + a.CfgFileCmdArg)
+	return strings.ToLower(strings.TrimSpace(format))
+}
+
+// ConfigFiles 最近一次 Run 或重新加载实际加载的配置文件
+func (a *AppArgs) ConfigFiles() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cfgFiles
+}
+
+// expandConfigDirs 将配置目录（如 conf.d）展开为其中支持格式的文件，按文件名字典序排列。
+// 启用 profile 时，目录中激活或声明的 profile 的覆盖文件不展开，激活的覆盖文件随对应的配置文件加载
+func (a *AppArgs) expandConfigDirs(set *flag.FlagSet, paths []string) ([]string, error) {
+	var files []string
+	for _, filePath := range paths {
+		info, err := os.Stat(filePath)
+		if err != nil || !info.IsDir() {
+			files = append(files, filePath)
+			continue
+		}
+
+		entries, err := ioutil.ReadDir(filePath)
+		if err != nil {
+			return nil, &FileError{Kind: ErrFileRead, Path: filePath, Cause: err}
+		}
+		names := map[string]bool{}
+		for _, entry := range entries {
+			names[entry.Name()] = !entry.IsDir()
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			// <base>.<profile>.<ext> 为 profile 覆盖文件，激活时随 <base>.<ext> 加载
+			if profile := a.overlayProfile(entry.Name(), names); profile != "" {
+				if !containsString(a.profiles, profile) {
+					_, _ = fmt.Fprintf(set.Output(), "配置目录[%s]跳过未激活 profile 的文件 %s\n", filePath, entry.Name())
+				}
+				continue
+			}
+			name := path.Join(filePath, entry.Name())
+			if fileUnmarshal(name) == nil {
+				_, _ = fmt.Fprintf(set.Output(), "配置目录[%s]跳过不支持的文件 %s\n", filePath, entry.Name())
+				continue
+			}
+			files = append(files, name)
+		}
 	}
+	return files, nil
+}
 
-	extName := path.Ext(a.CfgFilePath)[1:]
-	var unmarshal func(data []byte, v interface{}) error
-	if extName == "json" {
-		unmarshal = json.Unmarshal
-	} else if extName == "yml" || extName == "yaml" {
-		unmarshal = yaml.Unmarshal
-	} else if extName == "toml" || extName == "ini" {
-		unmarshal = toml.Unmarshal
+// readConfigFile 读取并解码单个配置文件，路径为 - 时读取标准输入。
+// 格式优先使用 format 指定的格式，其次根据扩展名，扩展名缺失或不支持时根据内容判断
+func (a *AppArgs) readConfigFile(set *flag.FlagSet, filePath, format string) (map[string]interface{}, error) {
+	var f *Format
+	if format != "" {
+		var found bool
+		if f, found = LookupFormat(format); !found {
+			return nil, &FileError{Kind: ErrFileType, Path: filePath}
+		}
 	} else {
-		return ErrFileType
+		f = fileFormat(filePath)
 	}
 
-	fmt.Fprintf(set.Output(), "读取配置文件 %s\n", a.CfgFilePath)
-	file, err := os.Open(a.CfgFilePath)
+	fmt.Fprintf(set.Output(), "读取配置文件 %s\n", filePath)
+	var content []byte
+	var err error
+	if filePath == "-" {
+		content, err = a.readStdin()
+	} else {
+		file, openErr := os.Open(filePath)
+		if openErr != nil {
+			return nil, &FileError{Kind: ErrFileNotFound, Path: filePath, Cause: openErr}
+		}
+		defer file.Close()
+		content, err = ioutil.ReadAll(file)
+	}
 	if err != nil {
-		_, _ = fmt.Fprintf(set.Output(), "配置文件[%s]打开错误：%v\n", a.CfgFilePath, err)
-		return ErrFileNotFound
+		return nil, &FileError{Kind: ErrFileRead, Path: filePath, Cause: err}
 	}
 
-	content, err := ioutil.ReadAll(file)
-	if err != nil {
-		_, _ = fmt.Fprintf(set.Output(), "配置文件[%s]读取错误：%v\n", a.CfgFilePath, err)
-		return ErrFileRead
+	if f == nil {
+		var found bool
+		if f, found = LookupFormat(sniffFormat(content)); !found {
+			return nil, &FileError{Kind: ErrFileType, Path: filePath}
+		}
 	}
 
-	err = unmarshal(content, a.CfgData)
+	var data map[string]interface{}
+	err = f.Decoder(content, &data)
 	if err != nil {
-		_, _ = fmt.Fprintf(set.Output(), "配置文件[%s]解析错误：%v\n", a.CfgFilePath, err)
-		return ErrFileParse
+		return nil, newFileError(ErrFileParse, filePath, content, err)
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	tree := normalizeTree(data).(map[string]interface{})
+	if a.EnvExpand != ExpandNone {
+		if key, err := a.expandTree(tree, ""); err != nil {
+			return nil, &FileError{Kind: ErrEnvExpand, Path: filePath, Line: keyLine(content, key), Cause: fmt.Errorf("%s: %w", key, err)}
+		}
+	}
+	// 按格式专用 tag 命名的键（如 json:"dbHost"）统一为参数名，以便与其他格式的文件合并
+	if tagKey := formatTagKey(f.Name); tagKey != "" {
+		canonicalTree(reflect.TypeOf(a.CfgData), tree, tagKey)
+	}
+
+	output := func(format string, args ...interface{}) {
+		_, _ = fmt.Fprintf(set.Output(), format, args...)
+	}
+	var ignored map[string]bool
+	if f.Name == "xml" {
+		ignored = xmlRootAttrs(content)
+	}
+	if err := a.checkUnknownKeys(output, filePath, content, tree, ignored); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// pathsValue 配置文件路径参数，可重复指定或以逗号分隔多个路径
+type pathsValue struct {
+	paths []string
+	set   bool
+}
+
+func newPathsValue(value string) *pathsValue {
+	p := &pathsValue{}
+	_ = p.Set(value)
+	p.set = false
+	return p
+}
+
+func (p *pathsValue) String() string {
+	if p == nil {
+		return ""
+	}
+	return strings.Join(p.paths, ",")
+}
+
+// Set 首次在命令行指定时替换默认路径，之后追加
+func (p *pathsValue) Set(value string) error {
+	if !p.set {
+		p.paths = nil
+		p.set = true
+	}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			p.paths = append(p.paths, item)
+		}
 	}
 	return nil
 }
@@ -129,25 +429,52 @@ func getEnvName(name string) {
 }
 
 // parseEnvArg 解析环境变量参数
-func (a *AppArgs) parseEnvArg(set *flag.FlagSet, flags map[string]*StructArg) {
-	for name, f := range flags {
-		envName := a.getEnvName(name)
-
-		envValue, found := os.LookupEnv(envName)
+func (a *AppArgs) parseEnvArg(set *flag.FlagSet, flags map[string]*StructArg) error {
+	var errs MultiError
+	for _, name := range sortedArgNames(flags) {
+		f := flags[name]
+		envName, envValue, found, err := a.lookupArgEnv(f)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
 		if !found {
 			continue
 		}
 		v, err := typeValue(f, envValue)
 		if err != nil {
-			_, _ = fmt.Fprintf(set.Output(), "参数【%v=%v】解析错误：%v\n", envName, envValue, err)
+			errs = append(errs, &ValueError{Key: envName, Source: "env", Value: envValue, Cause: err})
 			continue
 		}
 		f.Set(v)
 	}
+	return errs.errorOrNil()
+}
+
+// lookupArgEnv 按顺序查找参数对应的环境变量，返回第一个找到的变量名及值
+func (a *AppArgs) lookupArgEnv(f *StructArg) (string, string, bool, error) {
+	for _, envName := range a.envNames(f) {
+		if envValue, found := a.lookupEnv(envName); found {
+			return envName, envValue, true, nil
+		}
+		if !f.FileRef {
+			continue
+		}
+		// 兼容 Docker/Kubernetes secrets 约定：XXX_FILE 指定参数值所在文件
+		if filePath, found := a.lookupEnv(envName + "_FILE"); found {
+			content, err := readValueFile(filePath)
+			if err != nil {
+				return "", "", false, &ValueError{Key: envName + "_FILE", Source: "env", Value: filePath, Cause: err}
+			}
+			return envName + "_FILE", content, true, nil
+		}
+	}
+	return "", "", false, nil
 }
 
 // 解析命令行参数
-func (a *AppArgs) parseCmdArg(set *flag.FlagSet, flags map[string]*StructArg) {
+func (a *AppArgs) parseCmdArg(set *flag.FlagSet, flags map[string]*StructArg) error {
+	var errs MultiError
 	set.Visit(func(f *flag.Flag) {
 		ff, found := flags[f.Name]
 		if !found {
@@ -160,14 +487,148 @@ func (a *AppArgs) parseCmdArg(set *flag.FlagSet, flags map[string]*StructArg) {
 		if argValue == "" {
 			return
 		}
+		if ff.FileRef {
+			content, err := a.readValueRef(argValue)
+			if err != nil {
+				errs = append(errs, &ValueError{Key: f.Name, Source: "cmd", Value: argValue, Cause: err})
+				return
+			}
+			argValue = content
+		}
 
 		v, err := typeValue(ff, argValue)
 		if err != nil {
-			_, _ = fmt.Fprintf(set.Output(), "参数【%v=%v】解析错误：%v\n", f.Name, argValue, err)
+			errs = append(errs, &ValueError{Key: f.Name, Source: "cmd", Value: argValue, Cause: err})
 			return
 		}
 		ff.Set(v)
 	})
+	return errs.errorOrNil()
+}
+
+// abbrevArgs 将唯一前缀缩写的参数名展开为完整参数名，如 -inner.n => -inner.name
+func abbrevArgs(set *flag.FlagSet, arguments []string) ([]string, error) {
+	expanded := make([]string, 0, len(arguments))
+	for i := 0; i < len(arguments); i++ {
+		arg := arguments[i]
+		// 与 flag 包一致：遇到 "--" 或非参数项即停止解析
+		if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+			return append(expanded, arguments[i:]...), nil
+		}
+
+		dashes := "-"
+		if arg[1] == '-' {
+			dashes = "--"
+		}
+		name := arg[len(dashes):]
+		value := ""
+		hasValue := false
+		if idx := strings.Index(name, "="); idx >= 0 {
+			name, value, hasValue = name[:idx], name[idx:], true
+		}
+		if name == "" || name[0] == '-' || name[0] == '=' || name == "h" || name == "help" {
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		if set.Lookup(name) == nil {
+			var candidates []string
+			set.VisitAll(func(f *flag.Flag) {
+				if strings.HasPrefix(f.Name, name) {
+					candidates = append(candidates, f.Name)
+				}
+			})
+			if len(candidates) > 1 {
+				return nil, fmt.Errorf("%w: -%s 可匹配 -%s", ErrArgAmbiguous, name, strings.Join(candidates, ", -"))
+			}
+			if len(candidates) == 1 {
+				name = candidates[0]
+			}
+		}
+		expanded = append(expanded, dashes+name+value)
+
+		// 所有参数均为字符串类型，未使用 = 时下一项为参数值
+		if !hasValue && set.Lookup(name) != nil && i+1 < len(arguments) {
+			i++
+			expanded = append(expanded, arguments[i])
+		}
+	}
+	return expanded, nil
+}
+
+// lookupEnv 查找环境变量，环境变量优先于 .env 文件中的值。
+// 未通过 EnvMap/EnvList/EnvLookup 指定环境时使用进程环境变量
+func (a *AppArgs) lookupEnv(name string) (string, bool) {
+	lookup := a.envLookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	if value, found := lookup(name); found {
+		return value, found
+	}
+	value, found := a.dotEnv[name]
+	return value, found
+}
+
+// environKeys 列出所有环境变量名，EnvLookup 指定的查找函数无法列出变量
+func (a *AppArgs) environKeys() []string {
+	var keys []string
+	if a.envLookup == nil {
+		for _, kv := range os.Environ() {
+			if idx := strings.Index(kv, "="); idx > 0 {
+				keys = append(keys, kv[:idx])
+			}
+		}
+	} else if a.envKeys != nil {
+		keys = a.envKeys()
+	}
+	for key := range a.dotEnv {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// readValueRef 读取引用形式的参数值：@path 或 file:path 读取文件，- 读取标准输入
+func (a *AppArgs) readValueRef(value string) (string, error) {
+	switch {
+	case value == "-":
+		content, err := a.readStdin()
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	case strings.HasPrefix(value, "@"):
+		return readValueFile(value[1:])
+	case strings.HasPrefix(value, "file:"):
+		return readValueFile(value[len("file:"):])
+	default:
+		return value, nil
+	}
+}
+
+// readStdin 读取标准输入（或 Input 指定的输入），内容在首次读取后缓存，重新加载时复用
+func (a *AppArgs) readStdin() ([]byte, error) {
+	if !a.stdinRead {
+		input := a.input
+		if input == nil {
+			input = os.Stdin
+		}
+		content, err := ioutil.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		a.stdin, a.stdinRead = content, true
+	}
+	return a.stdin, nil
+}
+
+// readValueFile 读取文件内容作为参数值，去掉末尾换行
+func readValueFile(filePath string) (string, error) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
 }
 
 // flagSet 参数解析 FlagSet
@@ -188,6 +649,12 @@ func (a *AppArgs) flagSet(name string, flags map[string]*StructArg) *flag.FlagSe
 		if a.Usage != "" {
 			_, _ = fmt.Fprintf(set.Output(), "  %s\n", a.Usage)
 		}
+		if paths := a.configPaths(set); len(paths) > 0 {
+			_, _ = fmt.Fprintf(set.Output(), "\n  Config files: %s\n", strings.Join(paths, ", "))
+		}
+		if profiles := a.activeProfiles(set); len(profiles) > 0 {
+			_, _ = fmt.Fprintf(set.Output(), "  Profiles: %s\n", strings.Join(profiles, ", "))
+		}
 		argsUsagePrefix := "        "
 		_, _ = fmt.Fprintf(set.Output(), "\n  -h, -help\n")
 		_, _ = fmt.Fprintf(set.Output(), argsUsagePrefix+"show usage\n")
@@ -197,12 +664,24 @@ func (a *AppArgs) flagSet(name string, flags map[string]*StructArg) *flag.FlagSe
 			ff, found := flags[f.Name]
 			if found {
 				tName = typeName(ff)
+			} else if f.Name == a.CfgFileCmdArg {
+				tName = "string"
 			}
 			if len(tName) > 0 {
 				s += " " + tName
 			}
 			// Env name
-			s += fmt.Sprintf(" \t (ENV: %s)", a.getEnvName(f.Name))
+			envNames := []string{a.getEnvName(f.Name)}
+			if found {
+				envNames = nil
+				for _, envName := range a.envNames(ff) {
+					envNames = append(envNames, envName)
+					if ff.FileRef {
+						envNames = append(envNames, envName+"_FILE")
+					}
+				}
+			}
+			s += fmt.Sprintf(" \t (ENV: %s)", strings.Join(envNames, ", "))
 			// Boolean flags of one ASCII letter are so common we
 			// treat them specially, putting their usage on the same line.
 			if len(s) <= 4 { // space, space, '-', 'x'.
@@ -231,14 +710,52 @@ func (a *AppArgs) flagSet(name string, flags map[string]*StructArg) *flag.FlagSe
 	return set
 }
 
+// getEnvName 参数名对应的环境变量名：. 替换为嵌套分隔符，- 替换为 _，加上前缀后转为大写
 func (a *AppArgs) getEnvName(name string) string {
-	envName := strings.ReplaceAll(name, ".", "_")
+	separator := a.EnvSeparator
+	if separator == "" {
+		separator = "_"
+	}
+	envName := strings.ReplaceAll(name, ".", separator)
+	envName = strings.ReplaceAll(envName, "-", "_")
 	if a.EnvPrefix != "" {
 		envName = a.EnvPrefix + "_" + envName
 	}
 	return strings.ToUpper(envName)
 }
 
+// envNames 参数对应的环境变量名，env tag 显式指定时按顺序作为候选，否则由参数名生成
+func (a *AppArgs) envNames(f *StructArg) []string {
+	if len(f.Env) > 0 {
+		return f.Env
+	}
+	return []string{a.getEnvName(f.Name)}
+}
+
+// sortedArgNames 按名称排序的参数名
+func sortedArgNames(flags map[string]*StructArg) []string {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkEnvNames 检查不同参数是否映射到同一个环境变量
+func (a *AppArgs) checkEnvNames(flags map[string]*StructArg) error {
+	owners := map[string]string{}
+	for _, name := range sortedArgNames(flags) {
+		for _, envName := range a.envNames(flags[name]) {
+			if owner, found := owners[envName]; found {
+				return fmt.Errorf("%w: %s 同时对应参数 %s 和 %s", ErrEnvConflict, envName, owner, name)
+			}
+			owners[envName] = name
+		}
+	}
+	return nil
+}
+
 // New 新建参数解析应用
 func New(name string, options ...Option) *AppArgs {
 
@@ -289,7 +806,16 @@ func Output(output io.Writer) Option {
 	}
 }
 
-// 文件配置参数
+// Input 参数值为 - 时读取的输入，默认 os.Stdin
+func Input(input io.Reader) Option {
+	return func(args *AppArgs) {
+		args.input = input
+	}
+}
+
+// 文件配置参数，参数可重复指定或以逗号分隔多个文件，按顺序深度合并。
+// 指定目录时加载目录下所有支持格式的文件（如 conf.d），指定 - 时读取标准输入。
+// 同时添加 argName-format 参数用于指定配置文件格式
 func FileConfigEnabled(argName, defaultValue string, require bool, usage string) Option {
 	return func(args *AppArgs) {
 		args.CfgFileCmdArg = argName
@@ -303,6 +829,147 @@ func FileConfigEnabled(argName, defaultValue string, require bool, usage string)
 	}
 }
 
+// EnvMap 使用 map 作为环境变量来源，替代进程环境变量
+func EnvMap(env map[string]string) Option {
+	return func(args *AppArgs) {
+		args.envLookup = func(name string) (string, bool) {
+			value, found := env[name]
+			return value, found
+		}
+		args.envKeys = func() []string {
+			keys := make([]string, 0, len(env))
+			for key := range env {
+				keys = append(keys, key)
+			}
+			return keys
+		}
+	}
+}
+
+// EnvList 使用 os.Environ 格式（KEY=VALUE）的列表作为环境变量来源，替代进程环境变量
+func EnvList(environ []string) Option {
+	env := map[string]string{}
+	for _, kv := range environ {
+		if idx := strings.Index(kv, "="); idx > 0 {
+			env[kv[:idx]] = kv[idx+1:]
+		}
+	}
+	return EnvMap(env)
+}
+
+// EnvLookup 使用查找函数作为环境变量来源，替代进程环境变量。
+// 查找函数无法列出所有变量，因此不支持 map 类型参数的环境变量
+func EnvLookup(lookup func(name string) (string, bool)) Option {
+	return func(args *AppArgs) {
+		args.envLookup = lookup
+		args.envKeys = nil
+	}
+}
+
+// EnvElementPolicy 指定带下标的环境变量（如 APP_SERVERS_0_HOST）与配置文件中集合元素的合并策略，
+// 默认 ElementMerge
+func EnvElementPolicy(policy ElementPolicy) Option {
+	return func(args *AppArgs) {
+		args.EnvElements = policy
+	}
+}
+
+// DotEnv 加载 .env 文件作为环境变量来源，不修改进程环境变量，真实环境变量优先。
+// 多个文件按顺序加载，后面的覆盖前面的，不存在的文件将被忽略
+func DotEnv(paths ...string) Option {
+	return func(args *AppArgs) {
+		args.DotEnvPaths = append(args.DotEnvPaths, paths...)
+	}
+}
+
+// ExpandEnv 替换配置文件字符串值中的 ${VAR} 变量引用，scope 指定替换范围
+func ExpandEnv(scope ExpandScope) Option {
+	return func(args *AppArgs) {
+		args.EnvExpand = scope
+	}
+}
+
+// ArgAbbrev 允许使用唯一前缀缩写命令行参数名，如 -inner.n 代替 -inner.name
+func ArgAbbrev() Option {
+	return func(args *AppArgs) {
+		args.ArgAbbrev = true
+	}
+}
+
+// Lenient 宽松模式：无法解析的参数值仅输出错误信息并忽略，Run 不返回这些错误
+func Lenient() Option {
+	return func(args *AppArgs) {
+		args.Lenient = true
+	}
+}
+
+// EnvSeparator 嵌套参数在环境变量名中的分隔符，默认 "_"。
+// 如使用 "__" 时 inner.name 对应 INNER__NAME，避免与 inner_name 冲突
+func EnvSeparator(separator string) Option {
+	return func(args *AppArgs) {
+		args.EnvSeparator = separator
+	}
+}
+
+// Strict 严格模式：配置文件中存在无法对应到参数对象字段的键时返回错误，默认仅输出警告
+func Strict() Option {
+	return func(args *AppArgs) {
+		args.CfgStrict = true
+	}
+}
+
+// Includes 允许配置文件通过 key 指定的键包含其他配置文件，如 include: [common.yaml, secrets.toml]。
+// 被包含的文件作为基础，当前文件中的值优先
+func Includes(key string) Option {
+	return func(args *AppArgs) {
+		args.CfgIncludeKey = key
+	}
+}
+
+// Profiles 启用 profile：通过命令行参数 argName 或对应的环境变量（如 APP_PROFILE）指定，多个以逗号分隔。
+// 激活的 profile 会在 config.yaml 之后加载 config.<profile>.yaml，并合并配置中 profiles.<profile> 段。
+// names 为可能使用的 profile，配置目录中这些 profile 的覆盖文件在未激活时也不会作为普通配置文件加载
+func Profiles(argName string, names ...string) Option {
+	return func(args *AppArgs) {
+		args.CfgProfileArg = argName
+		args.CfgProfileNames = append(args.CfgProfileNames, names...)
+	}
+}
+
+// SearchPaths 配置文件搜索路径，未在命令行指定配置文件时按顺序查找。
+// 路径支持 ~ 与 $VAR 展开，不带扩展名时依次尝试已注册格式的扩展名，
+// 如 SearchPaths(SearchFirst, "./app", "$XDG_CONFIG_HOME/app/config", "/etc/app/config")
+func SearchPaths(mode SearchMode, paths ...string) Option {
+	return func(args *AppArgs) {
+		args.CfgSearchMode = mode
+		args.CfgSearchPaths = append(args.CfgSearchPaths, paths...)
+	}
+}
+
+// ProjectConfig 从工作目录开始逐级向上查找名为 name 的项目配置文件（如 .toolrc.yaml），
+// 在包含任一停止标记（如 .git）的目录或根目录停止。merge 为 true 时合并沿途找到的所有文件，近的优先
+func ProjectConfig(name string, merge bool, markers ...string) Option {
+	return func(args *AppArgs) {
+		args.CfgProjectName = name
+		args.CfgProjectMerge = merge
+		args.CfgProjectMarkers = markers
+	}
+}
+
+// WorkDir 查找项目配置文件的起始目录，默认为当前工作目录
+func WorkDir(dir string) Option {
+	return func(args *AppArgs) {
+		args.WorkDir = dir
+	}
+}
+
+// MergeSlices 多个配置文件合并时切片的合并策略，默认 SliceReplace
+func MergeSlices(policy SlicePolicy) Option {
+	return func(args *AppArgs) {
+		args.SliceMerge = policy
+	}
+}
+
 // 环境变量配置
 func EnvArg(prefix string) Option {
 	return func(args *AppArgs) {
@@ -374,6 +1041,8 @@ type StructArg struct {
 	Default string
 	Usage   string
 	Require bool
+	FileRef bool
+	Env     []string
 	Set     func(value interface{})
 	TName   string
 }
@@ -386,13 +1055,13 @@ func Bean2Args(data interface{}) map[string]*StructArg {
 	if t.Kind() != reflect.Struct {
 		panic("不支持普通类型参数解析，请使用结构类型接收参数！！！")
 	}
-	bean2XPath(args, reflect.TypeOf(data), reflect.ValueOf(data), "", false, "")
+	bean2XPath(args, reflect.TypeOf(data), reflect.ValueOf(data), "", false, false, "")
 
 	return args
 }
 
 // bean2XPath 得到对象的 xpath
-func bean2XPath(args map[string]*StructArg, t reflect.Type, v reflect.Value, path string, require bool, usage string) {
+func bean2XPath(args map[string]*StructArg, t reflect.Type, v reflect.Value, path string, require, fileRef bool, usage string) {
 
 	t, v = realTV(t, v)
 
@@ -408,7 +1077,15 @@ func bean2XPath(args map[string]*StructArg, t reflect.Type, v reflect.Value, pat
 				}
 				usage := field.Tag.Get("usage")
 				_, require := field.Tag.Lookup("require")
-				bean2XPath(args, field.Type, v.Field(i), argName, require, usage)
+				_, fileRef := field.Tag.Lookup("file")
+				bean2XPath(args, field.Type, v.Field(i), argName, require, fileRef, usage)
+				if env := field.Tag.Get("env"); env != "" {
+					if f, found := args[argName]; found {
+						for _, envName := range strings.Split(env, ",") {
+							f.Env = append(f.Env, strings.TrimSpace(envName))
+						}
+					}
+				}
 			}
 		}
 	case reflect.Complex64,
@@ -432,6 +1109,7 @@ func bean2XPath(args map[string]*StructArg, t reflect.Type, v reflect.Value, pat
 			Usage:   usage,
 			Default: fmt.Sprintf("%v", v),
 			Require: require,
+			FileRef: fileRef,
 			Set: func(value interface{}) {
 				v.Set(reflect.ValueOf(value))
 			},
@@ -439,21 +1117,28 @@ func bean2XPath(args map[string]*StructArg, t reflect.Type, v reflect.Value, pat
 	}
 }
 
+// argTagKeys 按优先级排列的参数名 tag
+var argTagKeys = []string{"yaml", "json", "toml", "xml"}
+
 // tagArgName 获取 tag 配置的参数信息
 func tagArgName(field reflect.StructField) (string, bool) {
-	if name, found := field.Tag.Lookup("yaml"); found {
-		return name, found
-	} else if name, found := field.Tag.Lookup("json"); found {
-		return name, found
-	} else if name, found := field.Tag.Lookup("toml"); found {
-		return name, found
+	for _, key := range argTagKeys {
+		if tag, found := field.Tag.Lookup(key); found {
+			name := strings.Split(tag, ",")[0]
+			if name == "-" {
+				return "", false
+			}
+			if name != "" {
+				return name, true
+			}
+		}
 	}
 	return "", false
 }
 
 // realType 得到指针类型的真实类型
 func realTV(t reflect.Type, v reflect.Value) (reflect.Type, reflect.Value) {
-	for ; t.Kind() == reflect.Ptr; {
+	for t.Kind() == reflect.Ptr {
 		t = t.Elem()
 		v = v.Elem()
 	}
@@ -1,8 +1,10 @@
 package args
 
 import (
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -50,3 +52,62 @@ func TestCmdOverride(t *testing.T) {
 	_ = os.Unsetenv("TEST_INNER_NAME")
 	_ = os.Unsetenv("TEST_INNER_ARG")
 }
+
+func TestCmdAbbrev(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{
+		"test-app",
+		"-na=test-cmd-name",
+		"--inner.n", "test-cmd-inner-name",
+		"-inner.ar=555",
+	}
+
+	appArgs := New(args[0], Store(testCfg), ArgAbbrev())
+
+	err := appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "test-cmd-name", testCfg.Name)
+	assert.Equal(t, "test-cmd-inner-name", testCfg.InnerArg.Name)
+	assert.Equal(t, 555, testCfg.InnerArg.Arg)
+}
+
+func TestCmdAbbrevAmbiguous(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-inner.a=1"}
+
+	appArgs := New(args[0], Store(testCfg), ArgAbbrev())
+
+	err := appArgs.Run(args)
+	assert.True(t, errors.Is(err, ErrArgAmbiguous))
+	assert.Equal(t, "参数缩写不明确: -inner.a 可匹配 -inner.age, -inner.arg", err.Error())
+
+	appArgs = New(args[0], Store(testCfg))
+	err = appArgs.Run(args)
+	assert.True(t, errors.Is(err, ErrCmdParse))
+}
+
+type TestSecretArg struct {
+	User     string `yaml:"user" json:"user" toml:"user"`
+	Password string `yaml:"password" json:"password" toml:"password" file:""`
+}
+
+func TestCmdValueFile(t *testing.T) {
+	testCfg := &TestSecretArg{}
+	args := []string{"test-app", "-user=@test_data/secret.txt", "-password=@test_data/secret.txt"}
+	err := New(args[0], Store(testCfg)).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "@test_data/secret.txt", testCfg.User)
+	assert.Equal(t, "s3cr3t", testCfg.Password)
+
+	testCfg = &TestSecretArg{}
+	args = []string{"test-app", "-password=file:test_data/secret.txt"}
+	err = New(args[0], Store(testCfg)).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cr3t", testCfg.Password)
+
+	testCfg = &TestSecretArg{}
+	args = []string{"test-app", "-password=-"}
+	err = New(args[0], Store(testCfg), Input(strings.NewReader("from-stdin\r\n"))).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "from-stdin", testCfg.Password)
+}
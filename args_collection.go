@@ -0,0 +1,234 @@
+package args
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ElementPolicy 环境变量提供的集合元素与配置文件中元素的合并策略。
+// 切片下标不要求连续，长度由环境变量中最大的下标决定，环境变量未提供的元素
+// 在 ElementMerge 下保留原有元素（没有时为零值），在 ElementReplace 下为零值
+type ElementPolicy int
+
+const (
+	// ElementMerge 按下标/键合并：环境变量只覆盖对应元素中出现的字段，超出部分追加
+	ElementMerge ElementPolicy = iota
+	// ElementReplace 只要环境变量提供了任一元素，整个集合即由环境变量中的元素替换
+	ElementReplace
+)
+
+// collectionArg 元素为结构体的切片或 map 参数
+type collectionArg struct {
+	Name  string
+	Value reflect.Value
+}
+
+// bean2Collections 获取对象中元素为结构体的切片及 map[string] 字段
+func bean2Collections(data interface{}) []*collectionArg {
+	var collections []*collectionArg
+	bean2CollectionPath(&collections, reflect.TypeOf(data), reflect.ValueOf(data), "")
+	return collections
+}
+
+func bean2CollectionPath(collections *[]*collectionArg, t reflect.Type, v reflect.Value, path string) {
+	for t.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		t, v = t.Elem(), v.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if argName, found := tagArgName(field); found {
+				if path != "" {
+					argName = path + "." + argName
+				}
+				bean2CollectionPath(collections, field.Type, v.Field(i), argName)
+			}
+		}
+	case reflect.Slice, reflect.Map:
+		if t.Kind() == reflect.Map && t.Key().Kind() != reflect.String {
+			return
+		}
+		if elemStructType(t.Elem()) != nil && v.CanSet() {
+			*collections = append(*collections, &collectionArg{Name: path, Value: v})
+		}
+	}
+}
+
+// elemStructType 元素为结构体或结构体指针时返回结构体类型
+func elemStructType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// parseEnvCollectionArg 解析带下标的环境变量参数，如 APP_SERVERS_0_HOST、APP_DB_PRIMARY_HOST
+func (a *AppArgs) parseEnvCollectionArg(cfg interface{}) error {
+	var errs MultiError
+	for _, c := range bean2Collections(cfg) {
+		if c.Value.Kind() == reflect.Slice {
+			errs = appendError(errs, a.parseEnvSlice(c))
+		} else {
+			errs = appendError(errs, a.parseEnvMap(c))
+		}
+	}
+	return errs.errorOrNil()
+}
+
+func (a *AppArgs) parseEnvSlice(c *collectionArg) error {
+	// 环境变量中出现的最大下标
+	last := -1
+	for _, key := range a.envMapKeys(c) {
+		if i, err := strconv.Atoi(key); err == nil && i > last {
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil
+	}
+
+	existing := c.Value
+	size := last + 1
+	if a.EnvElements == ElementMerge && existing.Len() > size {
+		size = existing.Len()
+	}
+	slice := reflect.MakeSlice(existing.Type(), size, size)
+	var errs MultiError
+	for i := 0; i < size; i++ {
+		var base reflect.Value
+		if a.EnvElements == ElementMerge && i < existing.Len() {
+			base = existing.Index(i)
+		}
+		elem, _, err := a.parseEnvElement(c.Name+"."+strconv.Itoa(i), existing.Type().Elem(), base)
+		errs = appendError(errs, err)
+		slice.Index(i).Set(elem)
+	}
+	c.Value.Set(slice)
+	return errs.errorOrNil()
+}
+
+func (a *AppArgs) parseEnvMap(c *collectionArg) error {
+	keys := a.envMapKeys(c)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	existing := c.Value
+	m := reflect.MakeMap(existing.Type())
+	var errs MultiError
+	if a.EnvElements == ElementMerge && !existing.IsNil() {
+		iter := existing.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), iter.Value())
+		}
+	}
+	for _, key := range keys {
+		mapKey := reflect.ValueOf(key).Convert(existing.Type().Key())
+		var base reflect.Value
+		if a.EnvElements == ElementMerge && !existing.IsNil() {
+			base = existing.MapIndex(mapKey)
+		}
+		elem, _, err := a.parseEnvElement(c.Name+"."+key, existing.Type().Elem(), base)
+		errs = appendError(errs, err)
+		m.SetMapIndex(mapKey, elem)
+	}
+	c.Value.Set(m)
+	return errs.errorOrNil()
+}
+
+// envMapKeys 从环境变量名中找出 map 的键，键统一为小写
+func (a *AppArgs) envMapKeys(c *collectionArg) []string {
+	separator := a.EnvSeparator
+	if separator == "" {
+		separator = "_"
+	}
+	prefix := a.getEnvName(c.Name) + separator
+
+	// 元素字段在环境变量名中的后缀，优先匹配最长的后缀。读取文件的字段同样匹配 XXX_FILE 形式的变量
+	var suffixes []string
+	for name, f := range Bean2Args(reflect.New(elemStructType(c.Value.Type().Elem())).Interface()) {
+		suffix := separator + strings.ToUpper(strings.ReplaceAll(name, ".", separator))
+		suffixes = append(suffixes, suffix)
+		if f.FileRef {
+			suffixes = append(suffixes, suffix+"_FILE")
+		}
+	}
+	sort.Slice(suffixes, func(i, j int) bool { return len(suffixes[i]) > len(suffixes[j]) })
+
+	keySet := map[string]bool{}
+	for _, envName := range a.environKeys() {
+		if !strings.HasPrefix(envName, prefix) {
+			continue
+		}
+		rest := envName[len(prefix):]
+		for _, suffix := range suffixes {
+			if len(rest) > len(suffix) && strings.HasSuffix(rest, suffix) {
+				keySet[strings.ToLower(rest[:len(rest)-len(suffix)])] = true
+				break
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseEnvElement 以 base 为基础解析一个集合元素，返回新元素及找到的环境变量个数
+func (a *AppArgs) parseEnvElement(path string, elemType reflect.Type, base reflect.Value) (reflect.Value, int, error) {
+	structType := elemStructType(elemType)
+	elem := reflect.New(structType)
+	if base.IsValid() {
+		if base.Kind() == reflect.Ptr {
+			if !base.IsNil() {
+				elem.Elem().Set(base.Elem())
+			}
+		} else {
+			elem.Elem().Set(base)
+		}
+	}
+
+	flags := map[string]*StructArg{}
+	bean2XPath(flags, structType, elem.Elem(), path, false, false, "")
+	n := 0
+	var errs MultiError
+	for _, name := range sortedArgNames(flags) {
+		f := flags[name]
+		// 元素字段不使用 env tag 指定的变量名，避免各元素共用同一个变量
+		f.Env = nil
+		envName, envValue, found, err := a.lookupArgEnv(f)
+		if err != nil {
+			n++
+			errs = append(errs, err)
+			continue
+		}
+		if !found {
+			continue
+		}
+		n++
+		v, err := typeValue(f, envValue)
+		if err != nil {
+			errs = append(errs, &ValueError{Key: envName, Source: "env", Value: envValue, Cause: err})
+			continue
+		}
+		f.Set(v)
+	}
+
+	if elemType.Kind() == reflect.Ptr {
+		return elem, n, errs.errorOrNil()
+	}
+	return elem.Elem(), n, errs.errorOrNil()
+}
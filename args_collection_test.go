@@ -0,0 +1,83 @@
+package args
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type TestServer struct {
+	Host string `yaml:"host" json:"host" toml:"host" file:""`
+	Port int    `yaml:"port" json:"port" toml:"port"`
+}
+
+type TestCollectionArg struct {
+	Servers []TestServer           `yaml:"servers" json:"servers" toml:"servers"`
+	DB      map[string]*TestServer `yaml:"db" json:"db" toml:"db"`
+}
+
+func TestEnvCollection(t *testing.T) {
+	env := map[string]string{
+		"APP_SERVERS_1_PORT":   "8081",
+		"APP_SERVERS_2_HOST":   "env-host-2",
+		"APP_DB_PRIMARY_PORT":  "6432",
+		"APP_DB_EU_WEST_HOST":  "env-eu-west",
+		"APP_DB_REPLICA_HOST":  "env-replica",
+		"APP_DB_REPLICA_OTHER": "ignored",
+	}
+	args := []string{"test-app", "-config=test_data/test-servers.yaml"}
+
+	testCfg := &TestCollectionArg{}
+	err := New(args[0], Store(testCfg), EnvArg("app"), EnvMap(env),
+		FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []TestServer{
+		{Host: "file-host-0", Port: 80},
+		{Host: "file-host-1", Port: 8081},
+		{Host: "env-host-2"},
+	}, testCfg.Servers)
+	assert.Equal(t, map[string]*TestServer{
+		"primary": {Host: "file-primary", Port: 6432},
+		"eu_west": {Host: "env-eu-west"},
+		"replica": {Host: "env-replica"},
+	}, testCfg.DB)
+
+	testCfg = &TestCollectionArg{}
+	err = New(args[0], Store(testCfg), EnvArg("app"), EnvMap(env),
+		EnvElementPolicy(ElementReplace),
+		FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	// 缺少下标 0 时同样替换，未提供的元素为零值
+	assert.Equal(t, []TestServer{
+		{},
+		{Port: 8081},
+		{Host: "env-host-2"},
+	}, testCfg.Servers)
+	assert.Equal(t, map[string]*TestServer{
+		"primary": {Port: 6432},
+		"eu_west": {Host: "env-eu-west"},
+		"replica": {Host: "env-replica"},
+	}, testCfg.DB)
+
+	env["APP_SERVERS_0_HOST"] = "env-host-0"
+	testCfg = &TestCollectionArg{}
+	err = New(args[0], Store(testCfg), EnvArg("app"), EnvMap(env),
+		EnvElementPolicy(ElementReplace),
+		FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []TestServer{
+		{Host: "env-host-0"},
+		{Port: 8081},
+		{Host: "env-host-2"},
+	}, testCfg.Servers)
+
+	// 下标可以超出配置文件中的元素且不连续，XXX_FILE 形式的变量同样有效
+	env = map[string]string{"APP_SERVERS_4_HOST_FILE": "test_data/secret.txt"}
+	testCfg = &TestCollectionArg{}
+	err = New(args[0], Store(testCfg), EnvArg("app"), EnvMap(env),
+		FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Len(t, testCfg.Servers, 5)
+	assert.Equal(t, TestServer{Host: "file-host-1", Port: 81}, testCfg.Servers[1])
+	assert.Equal(t, TestServer{}, testCfg.Servers[3])
+	assert.Equal(t, "s3cr3t", testCfg.Servers[4].Host)
+}
@@ -0,0 +1,149 @@
+package args
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+var ErrDotEnvParse = errors.New(".env 文件解析错误")
+
+// loadDotEnv 依次加载 .env 文件，后加载的文件覆盖先加载的同名变量。不存在的文件将被忽略
+func loadDotEnv(paths []string) (map[string]string, error) {
+	env := map[string]string{}
+	for _, p := range paths {
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, &FileError{Kind: ErrDotEnvParse, Path: p, Cause: err}
+		}
+		values, err := parseDotEnv(string(content))
+		if err != nil {
+			return nil, newFileError(ErrDotEnvParse, p, content, err)
+		}
+		for k, v := range values {
+			env[k] = v
+		}
+	}
+	return env, nil
+}
+
+// parseDotEnv 解析 .env 格式内容
+//
+// 支持：# 注释、export 前缀、单引号（原样）、双引号（支持 \n \t \" \\ 转义）以及跨行的引号值
+func parseDotEnv(content string) (map[string]string, error) {
+	env := map[string]string{}
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "export\t") {
+			line = strings.TrimSpace(line[len("export "):])
+		}
+
+		idx := strings.Index(line, "=")
+		if idx < 0 {
+			return nil, &syntaxError{lineNo, "缺少 '='"}
+		}
+		key := strings.TrimSpace(line[:idx])
+		if !validEnvKey(key) {
+			return nil, &syntaxError{lineNo, fmt.Sprintf("无效的变量名 %q", key)}
+		}
+		value := strings.TrimLeft(line[idx+1:], " \t")
+
+		if value == "" || (value[0] != '"' && value[0] != '\'') {
+			// 未加引号的值：空白后的 # 为行内注释
+			if c := strings.Index(value, " #"); c >= 0 {
+				value = value[:c]
+			} else if c := strings.Index(value, "\t#"); c >= 0 {
+				value = value[:c]
+			}
+			env[key] = strings.TrimSpace(value)
+			continue
+		}
+
+		// 带引号的值，可能跨越多行
+		quote := value[0]
+		raw := value[1:]
+		for {
+			end := closingQuote(raw, quote)
+			if end >= 0 {
+				rest := strings.TrimSpace(raw[end+1:])
+				if rest != "" && !strings.HasPrefix(rest, "#") {
+					return nil, &syntaxError{i + 1, fmt.Sprintf("引号后存在多余内容 %q", rest)}
+				}
+				raw = raw[:end]
+				break
+			}
+			i++
+			if i >= len(lines) {
+				return nil, &syntaxError{lineNo, "引号未闭合"}
+			}
+			raw += "\n" + lines[i]
+		}
+		if quote == '"' {
+			raw = unescapeDoubleQuoted(raw)
+		}
+		env[key] = raw
+	}
+	return env, nil
+}
+
+// closingQuote 返回结束引号位置，双引号内的 \" 不作为结束引号
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeDoubleQuoted(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '"', '\\', '$':
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func validEnvKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, c := range key {
+		if c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package args
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func Test_ParseDotEnv(t *testing.T) {
+	env, err := parseDotEnv(`
+# comment
+export A=1
+B = two words # comment
+C="line1
+line2\tend"
+D='literal \n # kept'
+E=
+F="a#b" # comment
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"A": "1",
+		"B": "two words",
+		"C": "line1\nline2\tend",
+		"D": `literal \n # kept`,
+		"E": "",
+		"F": "a#b",
+	}, env)
+
+	_, err = parseDotEnv("A=1\nB\n")
+	assert.Equal(t, `line 2: 缺少 '='`, err.Error())
+
+	_, err = parseDotEnv("A=\"open\nB=1\n")
+	assert.Equal(t, "line 1: 引号未闭合", err.Error())
+}
+
+func TestDotEnv(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app"}
+	appArgs := New(args[0], Store(testCfg), EnvArg("test"),
+		DotEnv("test_data/not-found.env", "test_data/test.env"))
+	assert.Nil(t, os.Setenv("TEST_ARG", "8"))
+	err := appArgs.Run(args)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "dotenv-name", testCfg.Name)
+	assert.Equal(t, 8, testCfg.Arg)
+	assert.Equal(t, "multi\nline \"value\"", testCfg.InnerArg.Name)
+	assert.Equal(t, 77, testCfg.InnerArg.Arg)
+	_, found := os.LookupEnv("TEST_NAME")
+	assert.False(t, found)
+
+	_ = os.Unsetenv("TEST_ARG")
+}
+
+func TestDotEnvParseError(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app"}
+	err := New(args[0], Store(testCfg), DotEnv("test_data/error-format.yaml")).Run(args)
+	assert.True(t, errors.Is(err, ErrDotEnvParse))
+	var fileErr *FileError
+	assert.True(t, errors.As(err, &fileErr))
+	assert.Equal(t, "test_data/error-format.yaml", fileErr.Path)
+	assert.True(t, fileErr.Line > 0)
+}
@@ -1,6 +1,7 @@
 package args
 
 import (
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"os"
 	"testing"
@@ -66,3 +67,95 @@ func TestEnvOverride(t *testing.T) {
 	_ = os.Unsetenv("NAME")
 	_ = os.Unsetenv("INNER_ARG")
 }
+
+func TestEnvValueFile(t *testing.T) {
+	testCfg := &TestSecretArg{}
+	args := []string{"test-app"}
+	appArgs := New(args[0], Store(testCfg), EnvArg("test"))
+	assert.Nil(t, os.Setenv("TEST_USER_FILE", "test_data/secret.txt"))
+	assert.Nil(t, os.Setenv("TEST_PASSWORD_FILE", "test_data/secret.txt"))
+	err := appArgs.Run(args)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", testCfg.User)
+	assert.Equal(t, "s3cr3t", testCfg.Password)
+
+	assert.Nil(t, os.Setenv("TEST_PASSWORD", "env-password"))
+	err = appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "env-password", testCfg.Password)
+
+	_ = os.Unsetenv("TEST_USER_FILE")
+	_ = os.Unsetenv("TEST_PASSWORD_FILE")
+	_ = os.Unsetenv("TEST_PASSWORD")
+}
+
+func TestEnvInjected(t *testing.T) {
+	tests := []struct {
+		name string
+		env  Option
+	}{
+		{"map", EnvMap(map[string]string{"APP_NAME": "map-name", "APP_INNER_ARG": "1"})},
+		{"list", EnvList([]string{"APP_NAME=list-name", "APP_INNER_ARG=2", "INVALID"})},
+		{"lookup", EnvLookup(func(name string) (string, bool) {
+			if name == "APP_NAME" {
+				return "lookup-name", true
+			}
+			return "", false
+		})},
+	}
+	want := map[string]*TestArg1{
+		"map":    {Name: "map-name", InnerArg: &TestInnerArg{Arg: 1}},
+		"list":   {Name: "list-name", InnerArg: &TestInnerArg{Arg: 2}},
+		"lookup": {Name: "lookup-name", InnerArg: &TestInnerArg{}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+			err := New("test-app", Store(testCfg), EnvArg("app"), tt.env).Run([]string{"test-app"})
+			assert.Nil(t, err)
+			assert.Equal(t, want[tt.name], testCfg)
+		})
+	}
+}
+
+type TestEnvNameArg struct {
+	URL       string        `yaml:"url" env:"DATABASE_URL, DB_URL"`
+	InnerName string        `yaml:"inner_name"`
+	Inner     *TestInnerArg `yaml:"inner"`
+}
+
+func TestEnvNames(t *testing.T) {
+	testCfg := &TestEnvNameArg{Inner: &TestInnerArg{}}
+	args := []string{"test-app"}
+	env := map[string]string{
+		"DB_URL":             "db-url",
+		"APP_INNER_NAME":     "inner_name",
+		"APP_INNER__NAME":    "inner.name",
+		"APP_INNER__ARG":     "3",
+		"APP_URL":            "ignored",
+		"APP_INNER_ARG":      "4",
+		"APP_INNER__NOT_SET": "5",
+	}
+	err := New(args[0], Store(testCfg), EnvArg("app"), EnvSeparator("__"), EnvMap(env)).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "db-url", testCfg.URL)
+	assert.Equal(t, "inner_name", testCfg.InnerName)
+	assert.Equal(t, "inner.name", testCfg.Inner.Name)
+	assert.Equal(t, 3, testCfg.Inner.Arg)
+
+	env["DATABASE_URL"] = "database-url"
+	err = New(args[0], Store(testCfg), EnvArg("app"), EnvSeparator("__"), EnvMap(env)).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "database-url", testCfg.URL)
+}
+
+func TestEnvNameConflict(t *testing.T) {
+	testCfg := &TestEnvNameArg{Inner: &TestInnerArg{}}
+	args := []string{"test-app"}
+	err := New(args[0], Store(testCfg), EnvMap(nil)).Run(args)
+	assert.True(t, errors.Is(err, ErrEnvConflict))
+	assert.Equal(t, "环境变量名冲突: INNER_NAME 同时对应参数 inner.name 和 inner_name", err.Error())
+}
@@ -0,0 +1,174 @@
+package args
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrArgValue 参数值解析错误
+var ErrArgValue = errors.New("参数值解析错误")
+
+// FileError 配置文件错误，Kind 为 ErrFileNotFound、ErrFileParse 等哨兵错误，
+// Line、Column 从 1 开始，未知时为 0
+type FileError struct {
+	Kind   error
+	Path   string
+	Line   int
+	Column int
+	Cause  error
+}
+
+func (e *FileError) Error() string {
+	location := e.Path
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, e.Line)
+		if e.Column > 0 {
+			location = fmt.Sprintf("%s:%d", location, e.Column)
+		}
+	}
+	switch {
+	case e.Cause == nil:
+		return fmt.Sprintf("%v: %s", e.Kind, location)
+	case e.Kind == nil || errors.Is(e.Cause, e.Kind):
+		return fmt.Sprintf("%s: %v", location, e.Cause)
+	}
+	return fmt.Sprintf("%v: %s: %v", e.Kind, location, e.Cause)
+}
+
+// Unwrap 返回底层错误
+func (e *FileError) Unwrap() error {
+	return e.Cause
+}
+
+// Is 判断是否属于 target 类型的配置文件错误
+func (e *FileError) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
+
+// ValueError 参数值错误，Source 为值的来源：file、env 或 cmd
+type ValueError struct {
+	Key    string
+	Source string
+	Value  interface{}
+	Cause  error
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("参数【%v=%v】解析错误：%v", e.Key, e.Value, e.Cause)
+}
+
+// Unwrap 返回底层错误
+func (e *ValueError) Unwrap() error {
+	return e.Cause
+}
+
+// Is 所有 ValueError 均属于 ErrArgValue
+func (e *ValueError) Is(target error) bool {
+	return target == ErrArgValue
+}
+
+// MultiError 多个错误的集合，用于一次报告所有错误的参数值
+type MultiError []error
+
+func (m MultiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is 任一错误匹配 target 即返回 true
+func (m MultiError) Is(target error) bool {
+	for _, err := range m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As 将第一个匹配 target 类型的错误赋值给 target
+func (m MultiError) As(target interface{}) bool {
+	for _, err := range m {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendError 追加错误，MultiError 会被展开
+func appendError(errs MultiError, err error) MultiError {
+	if multi, ok := err.(MultiError); ok {
+		return append(errs, multi...)
+	}
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// errorOrNil 没有错误时返回 nil，只有一个错误时返回该错误本身
+func (m MultiError) errorOrNil() error {
+	switch len(m) {
+	case 0:
+		return nil
+	case 1:
+		return m[0]
+	}
+	return m
+}
+
+// newFileError 创建配置文件错误，并尽量从解码错误中提取出错位置
+func newFileError(kind error, filePath string, content []byte, cause error) *FileError {
+	line, column := errorPosition(content, cause)
+	return &FileError{Kind: kind, Path: filePath, Line: line, Column: column, Cause: cause}
+}
+
+var errorLinePattern = regexp.MustCompile(`(?i)\bline (\d+)(?:,? column (\d+))?`)
+
+// errorPosition 从解码错误中提取行号和列号
+func errorPosition(content []byte, err error) (int, int) {
+	var syntaxErr *syntaxError
+	if errors.As(err, &syntaxErr) {
+		return syntaxErr.Line, 0
+	}
+	var jsonSyntaxErr *json.SyntaxError
+	if errors.As(err, &jsonSyntaxErr) {
+		return offsetPosition(content, jsonSyntaxErr.Offset)
+	}
+	var jsonTypeErr *json.UnmarshalTypeError
+	if errors.As(err, &jsonTypeErr) {
+		return offsetPosition(content, jsonTypeErr.Offset)
+	}
+	if err == nil {
+		return 0, 0
+	}
+	match := errorLinePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0, 0
+	}
+	line, _ := strconv.Atoi(match[1])
+	column, _ := strconv.Atoi(match[2])
+	return line, column
+}
+
+// offsetPosition 将字节偏移量转换为行号和列号
+func offsetPosition(content []byte, offset int64) (int, int) {
+	if offset <= 0 || int(offset) > len(content) {
+		return 0, 0
+	}
+	before := content[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n') - 1
+	if column == 0 {
+		column = 1
+	}
+	return line, column
+}
@@ -0,0 +1,127 @@
+package args
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFileError(t *testing.T) {
+	tests := []struct {
+		path   string
+		kind   error
+		line   int
+		column int
+	}{
+		{"test_data/not-found.yaml", ErrFileNotFound, 0, 0},
+		{"test_data/error-format.json", ErrFileParse, 2, 3},
+		{"test_data/error-format.ini", ErrFileParse, 2, 0},
+		{"test_data/error-type.xxx", ErrFileType, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+			args := []string{"test-app", "-config=" + tt.path}
+			err := New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, "")).Run(args)
+			assert.True(t, errors.Is(err, tt.kind))
+
+			var fileErr *FileError
+			assert.True(t, errors.As(err, &fileErr))
+			assert.Equal(t, tt.path, fileErr.Path)
+			assert.Equal(t, tt.line, fileErr.Line)
+			assert.Equal(t, tt.column, fileErr.Column)
+		})
+	}
+
+	var pathErr *os.PathError
+	err := New("test-app", Store(&TestArg1{InnerArg: &TestInnerArg{}}), FileConfigEnabled("config", "test_data/not-found.yaml", true, "")).Run([]string{"test-app"})
+	assert.True(t, errors.As(err, &pathErr))
+}
+
+func TestValueError(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/error-value.yaml"}
+	err := New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.True(t, errors.Is(err, ErrFileParse))
+	assert.True(t, errors.Is(err, ErrArgValue))
+
+	var valueErr *ValueError
+	assert.True(t, errors.As(err, &valueErr))
+	assert.Equal(t, "inner.age", valueErr.Key)
+	assert.Equal(t, "file", valueErr.Source)
+	assert.Equal(t, 300, valueErr.Value)
+	assert.Equal(t, "文件解析错误: test_data/error-value.yaml: 参数【inner.age=300】解析错误：无法将 int 类型的值转换为 uint8", err.Error())
+
+	// 多个配置文件时，错误指向最后设置该键的文件
+	for _, files := range [][]string{
+		{"test_data/test.yaml", "test_data/error-value.yaml"},
+		{"test_data/error-value.yaml", "test_data/test.yaml"},
+	} {
+		testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+		args = []string{"test-app", "-config=" + strings.Join(files, ",")}
+		err = New(args[0], Store(testCfg), Output(ioutil.Discard), FileConfigEnabled("config", "", true, "")).Run(args)
+		var fileErr *FileError
+		assert.True(t, errors.As(err, &fileErr))
+		assert.Equal(t, "test_data/error-value.yaml", fileErr.Path)
+	}
+
+	// 后面的文件覆盖了错误的值时不再报错
+	args = []string{"test-app", "-config=test_data/error-value.yaml,test_data/test-age.yaml"}
+	assert.Nil(t, New(args[0], Store(testCfg), Output(ioutil.Discard), FileConfigEnabled("config", "", true, "")).Run(args))
+	assert.Equal(t, uint8(9), testCfg.InnerArg.Age)
+}
+
+func Test_ErrorPosition(t *testing.T) {
+	line, column := errorPosition(nil, &syntaxError{Line: 3, Msg: "x"})
+	assert.Equal(t, []int{3, 0}, []int{line, column})
+
+	line, column = errorPosition(nil, errors.New("yaml: line 4: did not find expected key"))
+	assert.Equal(t, []int{4, 0}, []int{line, column})
+
+	line, column = errorPosition(nil, errors.New("Near line 5 (last key parsed 'a'): bare keys cannot contain ':'"))
+	assert.Equal(t, []int{5, 0}, []int{line, column})
+
+	line, column = errorPosition([]byte("a\nbc"), errors.New("unknown"))
+	assert.Equal(t, []int{0, 0}, []int{line, column})
+	assert.Equal(t, []int{2, 2}, func() []int { l, c := offsetPosition([]byte("a\nbc"), 4); return []int{l, c} }())
+}
+
+func TestMultiError(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/error-value.yaml", "-arg=x", "-inner.name=cmd"}
+	err := New(args[0], Store(testCfg), EnvMap(map[string]string{"INNER_ARG": "abc"}),
+		FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.True(t, errors.Is(err, ErrArgValue))
+	assert.True(t, errors.Is(err, ErrFileParse))
+
+	var multi MultiError
+	assert.True(t, errors.As(err, &multi))
+	assert.Len(t, multi, 3)
+
+	var sources []string
+	for _, e := range multi {
+		var valueErr *ValueError
+		assert.True(t, errors.As(e, &valueErr))
+		sources = append(sources, valueErr.Source+":"+valueErr.Key)
+	}
+	assert.Equal(t, []string{"file:inner.age", "env:INNER_ARG", "cmd:arg"}, sources)
+
+	// 其余参数值仍然生效
+	assert.Equal(t, "value-error", testCfg.Name)
+	assert.Equal(t, "cmd", testCfg.InnerArg.Name)
+}
+
+func TestLenient(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	output := &strings.Builder{}
+	args := []string{"test-app", "-arg=x", "-inner.name=cmd"}
+	err := New(args[0], Store(testCfg), Output(output), Lenient(),
+		EnvMap(map[string]string{"INNER_ARG": "abc"})).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "cmd", testCfg.InnerArg.Name)
+	assert.Contains(t, output.String(), "参数【INNER_ARG=abc】解析错误")
+	assert.Contains(t, output.String(), "参数【arg=x】解析错误")
+}
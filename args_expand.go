@@ -0,0 +1,171 @@
+package args
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrEnvExpand = errors.New("配置文件环境变量替换错误")
+
+// ExpandScope 配置文件环境变量替换范围
+type ExpandScope int
+
+const (
+	// ExpandNone 不替换
+	ExpandNone ExpandScope = iota
+	// ExpandPrefixed 仅替换以 EnvPrefix 开头的变量，其他引用原样保留
+	ExpandPrefixed
+	// ExpandAll 替换所有变量
+	ExpandAll
+)
+
+// expandEnv 替换字符串中的变量引用
+//
+// 支持语法：
+//
+//	${VAR}          变量值，未设置时为空
+//	${VAR:-default} 未设置或为空时使用 default
+//	${VAR-default}  未设置时使用 default
+//	${VAR:?message} 未设置或为空时报错
+//	${VAR?message}  未设置时报错
+//	$$              转义为 $
+func expandEnv(content string, lookup func(string) (string, bool), allowed func(string) bool) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if c != '$' || i+1 == len(content) {
+			b.WriteByte(c)
+			continue
+		}
+		switch content[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+			continue
+		case '{':
+		default:
+			b.WriteByte(c)
+			continue
+		}
+
+		end := closingBrace(content, i+2)
+		if end < 0 {
+			return "", fmt.Errorf("%w: %q 缺少 '}'", ErrEnvExpand, content[i:])
+		}
+		expr := content[i+2 : end]
+		name, op, arg := splitExpandExpr(expr)
+		if name == "" {
+			return "", fmt.Errorf("%w: ${%s} 缺少变量名", ErrEnvExpand, expr)
+		}
+		if !allowed(name) {
+			b.WriteString(content[i : end+1])
+			i = end
+			continue
+		}
+
+		value, found := lookup(name)
+		switch op {
+		case ":-", "-":
+			if !found || (op == ":-" && value == "") {
+				var err error
+				if value, err = expandEnv(arg, lookup, allowed); err != nil {
+					return "", err
+				}
+			}
+		case ":?", "?":
+			if !found || (op == ":?" && value == "") {
+				if arg == "" {
+					arg = "变量未设置"
+				}
+				return "", fmt.Errorf("%w: ${%s}: %s", ErrEnvExpand, name, arg)
+			}
+		}
+		b.WriteString(value)
+		i = end
+	}
+	return b.String(), nil
+}
+
+// expandTree 替换解码后配置中所有字符串值里的变量引用，键不做替换。
+// 出错时返回出错值的键路径
+func (a *AppArgs) expandTree(data interface{}, path string) (string, error) {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if s, ok := d[k].(string); ok {
+				expanded, err := expandEnv(s, a.lookupEnv, a.expandAllowed)
+				if err != nil {
+					return joinPath(path, k), err
+				}
+				d[k] = expanded
+				continue
+			}
+			if key, err := a.expandTree(d[k], joinPath(path, k)); err != nil {
+				return key, err
+			}
+		}
+	case []interface{}:
+		for i, v := range d {
+			if s, ok := v.(string); ok {
+				expanded, err := expandEnv(s, a.lookupEnv, a.expandAllowed)
+				if err != nil {
+					return joinPath(path, strconv.Itoa(i)), err
+				}
+				d[i] = expanded
+				continue
+			}
+			if key, err := a.expandTree(v, joinPath(path, strconv.Itoa(i))); err != nil {
+				return key, err
+			}
+		}
+	}
+	return "", nil
+}
+
+// closingBrace 返回与 start 之前的 '{' 匹配的 '}' 位置，支持默认值中嵌套 ${}
+func closingBrace(s string, start int) int {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitExpandExpr 拆分 VAR:-default 形式的表达式
+func splitExpandExpr(expr string) (name, op, arg string) {
+	for i := 0; i < len(expr); i++ {
+		switch expr[i] {
+		case ':':
+			if i+1 < len(expr) && (expr[i+1] == '-' || expr[i+1] == '?') {
+				return expr[:i], expr[i : i+2], expr[i+2:]
+			}
+		case '-', '?':
+			return expr[:i], expr[i : i+1], expr[i+1:]
+		}
+	}
+	return expr, "", ""
+}
+
+// expandAllowed 按照替换范围判断变量是否允许替换
+func (a *AppArgs) expandAllowed(name string) bool {
+	if a.EnvExpand == ExpandAll || a.EnvPrefix == "" {
+		return true
+	}
+	return strings.HasPrefix(name, strings.ToUpper(a.EnvPrefix)+"_")
+}
@@ -0,0 +1,110 @@
+package args
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func Test_ExpandEnv(t *testing.T) {
+	env := map[string]string{"A": "a", "EMPTY": "", "P_B": "b"}
+	lookup := func(name string) (string, bool) {
+		v, found := env[name]
+		return v, found
+	}
+	all := func(string) bool { return true }
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"${A}/x", "a/x"},
+		{"${NONE}", ""},
+		{"${NONE:-def}", "def"},
+		{"${EMPTY:-def}", "def"},
+		{"${EMPTY-def}", ""},
+		{"${NONE-${A}}", "a"},
+		{"$$A ${A} $A", "$A a $A"},
+	}
+	for _, tt := range tests {
+		got, err := expandEnv(tt.in, lookup, all)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	_, err := expandEnv("${EMPTY:?must set}", lookup, all)
+	assert.True(t, errors.Is(err, ErrEnvExpand))
+	assert.Equal(t, "配置文件环境变量替换错误: ${EMPTY}: must set", err.Error())
+	_, err = expandEnv("${EMPTY?must set}", lookup, all)
+	assert.Nil(t, err)
+	_, err = expandEnv("${A", lookup, all)
+	assert.True(t, errors.Is(err, ErrEnvExpand))
+
+	prefixed := func(name string) bool { return name == "P_B" }
+	got, err := expandEnv("${A}-${P_B}", lookup, prefixed)
+	assert.Nil(t, err)
+	assert.Equal(t, "${A}-b", got)
+}
+
+func TestExpandEnvFile(t *testing.T) {
+	assert.Nil(t, os.Setenv("TEST_ARG", "9"))
+	assert.Nil(t, os.Setenv("HOME_DIR", "/home/test"))
+
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test-expand.yaml"}
+	err := New(args[0], Store(testCfg),
+		FileConfigEnabled("config", "", true, ""),
+		ExpandEnv(ExpandAll)).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "default-name", testCfg.Name)
+	assert.Equal(t, 9, testCfg.Arg)
+	assert.Equal(t, "/home/test/data", testCfg.InnerArg.Name)
+	assert.Equal(t, 9, testCfg.InnerArg.Arg)
+
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	err = New(args[0], Store(testCfg),
+		FileConfigEnabled("config", "", true, ""),
+		EnvArg("test"),
+		ExpandEnv(ExpandPrefixed)).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "${HOME_DIR}/data", testCfg.InnerArg.Name)
+
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	args = []string{"test-app", "-config=test_data/test-expand-required.yaml"}
+	err = New(args[0], Store(testCfg),
+		FileConfigEnabled("config", "", false, ""),
+		ExpandEnv(ExpandAll)).Run(args)
+	assert.True(t, errors.Is(err, ErrEnvExpand))
+
+	_ = os.Unsetenv("TEST_ARG")
+	_ = os.Unsetenv("HOME_DIR")
+}
+
+func TestExpandEnvValues(t *testing.T) {
+	env := EnvMap(map[string]string{"TEST_QUOTE": `say "hi"`, "TEST_LINES": "x\narg: 99"})
+
+	// 变量值中的引号、换行不会破坏文件结构
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test-expand.json"}
+	err := New(args[0], Store(testCfg), env, FileConfigEnabled("config", "", true, ""), ExpandEnv(ExpandAll)).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, `say "hi"`, testCfg.Name)
+	assert.Equal(t, "x\narg: 99", testCfg.InnerArg.Name)
+	assert.Equal(t, 0, testCfg.Arg)
+
+	// 注释中的变量引用不做替换
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	args = []string{"test-app", "-config=test_data/test-expand-comment.yaml"}
+	err = New(args[0], Store(testCfg), env, FileConfigEnabled("config", "", true, ""), ExpandEnv(ExpandAll)).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "x\narg: 99", testCfg.Name)
+	assert.Equal(t, 0, testCfg.Arg)
+
+	args = []string{"test-app", "-config=test_data/test-expand-required.yaml"}
+	err = New(args[0], Store(testCfg), env, FileConfigEnabled("config", "", true, ""), ExpandEnv(ExpandAll)).Run(args)
+	var fileErr *FileError
+	assert.True(t, errors.As(err, &fileErr))
+	assert.Equal(t, 1, fileErr.Line)
+	assert.Equal(t, "test_data/test-expand-required.yaml:1: name: 配置文件环境变量替换错误: ${TEST_NAME}: TEST_NAME is required", err.Error())
+}
@@ -1,7 +1,10 @@
 package args
 
 import (
+	"bytes"
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 )
 
@@ -11,7 +14,7 @@ func Test_CmdArgParseError(t *testing.T) {
 	appArgs := New(args[0], Store(testCfg), FileConfigEnabled("config", "test-default.yaml", false, ""))
 
 	err := appArgs.Run(args[1:])
-	assert.Equal(t, ErrCmdParse, err)
+	assert.True(t, errors.Is(err, ErrCmdParse))
 }
 
 func Test_ConfigFileError(t *testing.T) {
@@ -21,21 +24,44 @@ func Test_ConfigFileError(t *testing.T) {
 		args := []string{"test-app", "-config=test_data/not-found.yaml"}
 		appArgs := New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, ""))
 		err := appArgs.Run(args)
-		assert.Equal(t, ErrFileNotFound, err)
+		assert.True(t, errors.Is(err, ErrFileNotFound))
 	}()
 
 	func() {
 		args := []string{"test-app", "-config=test_data/error-format.json"}
 		appArgs := New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, ""))
 		err := appArgs.Run(args)
-		assert.Equal(t, ErrFileParse, err)
+		assert.True(t, errors.Is(err, ErrFileParse))
+	}()
+
+	func() {
+		// 非必须的配置文件出错时不返回错误，但输出错误信息；文件不存在时不输出
+		output := &bytes.Buffer{}
+		args := []string{"test-app", "-config=test_data/error-format.json"}
+		appArgs := New(args[0], Store(testCfg), Output(output), FileConfigEnabled("config", "", false, ""))
+		err := appArgs.Run(args)
+		assert.Nil(t, err)
+		assert.Contains(t, output.String(), "配置文件加载失败：")
+		assert.Contains(t, output.String(), "test_data/error-format.json:2:3")
+
+		output.Reset()
+		args = []string{"test-app", "-config=test_data/not-found.yaml"}
+		appArgs = New(args[0], Store(testCfg), Output(output), FileConfigEnabled("config", "", false, ""))
+		assert.Nil(t, appArgs.Run(args))
+		assert.NotContains(t, output.String(), "配置文件加载失败")
+
+		output.Reset()
+		args = []string{"test-app"}
+		appArgs = New(args[0], Store(testCfg), Output(output), FileConfigEnabled("config", "", false, ""))
+		assert.Nil(t, appArgs.Run(args))
+		assert.Equal(t, "", output.String())
 	}()
 
 	func() {
 		args := []string{"test-app"}
 		appArgs := New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, ""))
 		err := appArgs.Run(args)
-		assert.NotNil(t, err)
+		assert.True(t, errors.Is(err, ErrFileArgMissing))
 		assert.Equal(t, "flag required but not provided: -config", err.Error())
 	}()
 
@@ -43,7 +69,7 @@ func Test_ConfigFileError(t *testing.T) {
 		args := []string{"test-app", "-config=test_data/error-type.xxx"}
 		appArgs := New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, ""))
 		err := appArgs.Run(args)
-		assert.Equal(t, ErrFileType, err)
+		assert.True(t, errors.Is(err, ErrFileType))
 	}()
 }
 
@@ -102,3 +128,119 @@ func Test_ConfigFileTomlFormat(t *testing.T) {
 	assert.Equal(t, "test-inner-name", testCfg.InnerArg.Name)
 	assert.Equal(t, 333, testCfg.InnerArg.Arg)
 }
+
+func Test_ConfigFileMultiple(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test.yaml,test_data/test.toml", "-config=test_data/test-override.json"}
+	appArgs := New(args[0], Store(testCfg), FileConfigEnabled("config", "test_data/test-default.yaml", true, ""))
+
+	err := appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "test_data/test.yaml,test_data/test.toml,test_data/test-override.json", appArgs.CfgFilePath)
+	assert.Equal(t, "test-name", testCfg.Name)
+	assert.Equal(t, 44, testCfg.Arg)
+	assert.Equal(t, "test-inner-name", testCfg.InnerArg.Name)
+	assert.Equal(t, 444, testCfg.InnerArg.Arg)
+}
+
+func Test_ConfigFileMergeSlices(t *testing.T) {
+	args := []string{"test-app", "-config=test_data/test-servers.yaml,test_data/test-servers-extra.toml"}
+
+	testCfg := &TestCollectionArg{}
+	err := New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []TestServer{{Host: "toml-host", Port: 90}}, testCfg.Servers)
+	assert.Equal(t, map[string]*TestServer{"primary": {Host: "file-primary", Port: 5432}}, testCfg.DB)
+
+	testCfg = &TestCollectionArg{}
+	err = New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, ""), MergeSlices(SliceAppend)).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []TestServer{
+		{Host: "file-host-0", Port: 80},
+		{Host: "file-host-1", Port: 81},
+		{Host: "toml-host", Port: 90},
+	}, testCfg.Servers)
+}
+
+func Test_ConfigFileDir(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	output := &bytes.Buffer{}
+	args := []string{"test-app", "-config=test_data/test.yaml,test_data/conf.d"}
+	appArgs := New(args[0], Store(testCfg), Output(output), FileConfigEnabled("config", "", true, ""))
+
+	err := appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "confd-name", testCfg.Name)
+	assert.Equal(t, 20, testCfg.Arg)
+	assert.Equal(t, "confd-inner-name", testCfg.InnerArg.Name)
+	assert.Equal(t, 300, testCfg.InnerArg.Arg)
+	assert.Contains(t, output.String(), "配置目录[test_data/conf.d]跳过不支持的文件 README.txt")
+}
+
+func Test_ConfigFileArgEnv(t *testing.T) {
+	env := EnvMap(map[string]string{"APP_CONFIG": "test_data/test.json,test_data/test-override.json"})
+
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app"}
+	appArgs := New(args[0], Store(testCfg), EnvArg("app"), env,
+		FileConfigEnabled("config", "test_data/test-default.yaml", true, ""))
+	err := appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"test_data/test.json", "test_data/test-override.json"}, appArgs.ConfigFiles())
+	assert.Equal(t, "test-name", testCfg.Name)
+	assert.Equal(t, 44, testCfg.Arg)
+
+	// 命令行参数优先于环境变量
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	args = []string{"test-app", "-config=test_data/test.toml"}
+	appArgs = New(args[0], Store(testCfg), EnvArg("app"), env,
+		FileConfigEnabled("config", "test_data/test-default.yaml", true, ""))
+	err = appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"test_data/test.toml"}, appArgs.ConfigFiles())
+
+	output := &bytes.Buffer{}
+	args = []string{"test-app", "-h"}
+	appArgs = New(args[0], Store(testCfg), EnvArg("app"), env, Output(output),
+		FileConfigEnabled("config", "test_data/test-default.yaml", true, ""))
+	assert.Equal(t, ErrHelp, appArgs.Run(args))
+	assert.Contains(t, output.String(), "-config string \t (ENV: APP_CONFIG)")
+	assert.Contains(t, output.String(), "Config files: test_data/test.json, test_data/test-override.json")
+}
+
+func Test_ConfigFileSniff(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test-sniff"}
+	err := New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "sniff-yaml-name", testCfg.Name)
+	assert.Equal(t, 5, testCfg.Arg)
+
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	args = []string{"test-app", "-config=test_data/test-sniff.conf"}
+	err = New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "sniff-toml-name", testCfg.Name)
+	assert.Equal(t, 6, testCfg.Arg)
+}
+
+func Test_ConfigFileStdin(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=-", "-config-format=json"}
+	input := strings.NewReader(`{"name": "stdin-name", "inner": {"arg": 7}}`)
+	err := New(args[0], Store(testCfg), Input(input), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "stdin-name", testCfg.Name)
+	assert.Equal(t, 7, testCfg.InnerArg.Arg)
+
+	// 格式参数同样支持环境变量，并优先于扩展名
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	args = []string{"test-app", "-config=test_data/test-sniff.conf"}
+	err = New(args[0], Store(testCfg), EnvMap(map[string]string{"CONFIG_FORMAT": "YAML"}),
+		FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.True(t, errors.Is(err, ErrFileParse))
+
+	args = []string{"test-app", "-config=test_data/test.yaml", "-config-format=xxx"}
+	err = New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.True(t, errors.Is(err, ErrFileType))
+}
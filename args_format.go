@@ -0,0 +1,285 @@
+package args
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Decoder 配置文件解码函数，签名与 json.Unmarshal 一致。
+// 配置文件会被解码到 *map[string]interface{} 后再合并写入参数对象
+type Decoder func(data []byte, v interface{}) error
+
+// Encoder 配置文件编码函数，签名与 json.Marshal 一致
+type Encoder func(v interface{}) ([]byte, error)
+
+// Format 配置文件格式
+type Format struct {
+	Name       string
+	Extensions []string
+	Decoder    Decoder
+	Encoder    Encoder
+}
+
+var formatRegistry = struct {
+	sync.RWMutex
+	formats []*Format
+}{}
+
+func init() {
+	RegisterFormat("json", []string{"json"}, jsonUnmarshal, json.Marshal)
+	RegisterFormat("yaml", []string{"yml", "yaml"}, yamlUnmarshal, yaml.Marshal)
+	RegisterFormat("toml", []string{"toml"}, toml.Unmarshal, tomlMarshal)
+	RegisterFormat("ini", []string{"ini"}, iniUnmarshal, nil)
+	RegisterFormat("properties", []string{"properties"}, propertiesUnmarshal, nil)
+	RegisterFormat("hcl", []string{"hcl"}, hclUnmarshal, nil)
+	RegisterFormat("xml", []string{"xml"}, xmlUnmarshal, xml.Marshal)
+}
+
+// RegisterFormat 注册配置文件格式，extensions 为不带 . 的扩展名。同名格式重复注册时替换原有格式
+func RegisterFormat(name string, extensions []string, decoder Decoder, encoder Encoder) {
+	format := &Format{Name: name, Extensions: extensions, Decoder: decoder, Encoder: encoder}
+
+	formatRegistry.Lock()
+	defer formatRegistry.Unlock()
+	for i, f := range formatRegistry.formats {
+		if f.Name == name {
+			formatRegistry.formats[i] = format
+			return
+		}
+	}
+	formatRegistry.formats = append(formatRegistry.formats, format)
+}
+
+// LookupFormat 按格式名或扩展名查找已注册的配置文件格式
+func LookupFormat(name string) (*Format, bool) {
+	name = strings.ToLower(name)
+	formatRegistry.RLock()
+	defer formatRegistry.RUnlock()
+	for _, f := range formatRegistry.formats {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	// 后注册的格式优先使用扩展名
+	for i := len(formatRegistry.formats) - 1; i >= 0; i-- {
+		f := formatRegistry.formats[i]
+		for _, ext := range f.Extensions {
+			if ext == name {
+				return f, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// formatNames 已注册的格式名
+func formatNames() []string {
+	formatRegistry.RLock()
+	defer formatRegistry.RUnlock()
+	names := make([]string, 0, len(formatRegistry.formats))
+	for _, f := range formatRegistry.formats {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// formatExtensions 已注册格式的扩展名，按注册顺序排列
+func formatExtensions() []string {
+	formatRegistry.RLock()
+	defer formatRegistry.RUnlock()
+	var extensions []string
+	for _, f := range formatRegistry.formats {
+		extensions = append(extensions, f.Extensions...)
+	}
+	return extensions
+}
+
+// fileFormat 根据文件扩展名获取配置文件格式，不支持时返回 nil
+func fileFormat(filePath string) *Format {
+	ext := strings.TrimPrefix(path.Ext(filePath), ".")
+	if ext == "" {
+		return nil
+	}
+	f, _ := LookupFormat(ext)
+	return f
+}
+
+// fileUnmarshal 根据文件扩展名获取解码函数，不支持时返回 nil
+func fileUnmarshal(filePath string) Decoder {
+	if f := fileFormat(filePath); f != nil {
+		return f.Decoder
+	}
+	return nil
+}
+
+// fileTypeError 配置文件类型不支持错误，错误信息列出已注册格式的扩展名
+type fileTypeError struct{}
+
+func (fileTypeError) Error() string {
+	return "文件类型不支持。仅支持：【" + strings.Join(formatExtensions(), "/") + "】"
+}
+
+// jsonUnmarshal 解码 JSON，数值保留为 json.Number，避免大整数经 float64 丢失精度
+func jsonUnmarshal(data []byte, v interface{}) error {
+	if !json.Valid(data) {
+		// 由 json.Unmarshal 给出带位置的语法错误
+		return json.Unmarshal(data, v)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// yamlUnmarshal 解码 YAML，解码到 *map[string]interface{} 时保留非字符串标量的原文，
+// 写入字符串字段时与直接解码到结构体一致：1.10、NO 保持原样，而不是 1.1、false
+func yamlUnmarshal(data []byte, v interface{}) error {
+	out, ok := v.(*map[string]interface{})
+	if !ok {
+		return yaml.Unmarshal(data, v)
+	}
+	var node yamlNode
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	switch tree := node.value.(type) {
+	case nil:
+		*out = nil
+	case map[string]interface{}:
+		*out = tree
+	default:
+		// 顶层不是映射，由 yaml.Unmarshal 给出类型错误
+		return yaml.Unmarshal(data, v)
+	}
+	return nil
+}
+
+// yamlScalar YAML 中解析为非字符串的标量，text 为文件中的原文
+type yamlScalar struct {
+	value interface{}
+	text  string
+}
+
+func (s yamlScalar) String() string {
+	return s.text
+}
+
+// MarshalYAML 重新编码时使用解析后的值
+func (s yamlScalar) MarshalYAML() (interface{}, error) {
+	return s.value, nil
+}
+
+// MarshalJSON 重新编码时使用解析后的值
+func (s yamlScalar) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.value)
+}
+
+// yamlNode 解码 YAML 的节点：映射解码为 map[string]interface{}，序列解码为 []interface{}，
+// 非字符串的标量解码为 yamlScalar
+type yamlNode struct {
+	value interface{}
+}
+
+func (n *yamlNode) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var m map[interface{}]*yamlNode
+	if err := unmarshal(&m); err == nil {
+		tree := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			tree[fmt.Sprintf("%v", k)] = v.get()
+		}
+		n.value = tree
+		return nil
+	}
+	var items []*yamlNode
+	if err := unmarshal(&items); err == nil {
+		values := make([]interface{}, len(items))
+		for i, item := range items {
+			values[i] = item.get()
+		}
+		n.value = values
+		return nil
+	}
+
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	n.value = value
+	if _, ok := value.(string); ok || value == nil {
+		return nil
+	}
+	var text string
+	if err := unmarshal(&text); err == nil {
+		n.value = yamlScalar{value: value, text: text}
+	}
+	return nil
+}
+
+// get 节点的值，null 节点不会被解码，为 nil
+func (n *yamlNode) get() interface{} {
+	if n == nil {
+		return nil
+	}
+	return n.value
+}
+
+// formatTagKey 格式专用的 tag 名，如 json 格式优先使用 json tag 中的键名，没有专用 tag 时返回空字符串
+func formatTagKey(format string) string {
+	for _, key := range argTagKeys {
+		if key == format {
+			return key
+		}
+	}
+	return ""
+}
+
+func tomlMarshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var (
+	tomlTableLine = regexp.MustCompile(`^\[\[?\s*[A-Za-z0-9_."'-]+(\s*\.\s*[A-Za-z0-9_."'-]+)*\s*\]\]?\s*(#.*)?$`)
+	tomlKeyLine   = regexp.MustCompile(`^[A-Za-z0-9_."'-]+(\s*\.\s*[A-Za-z0-9_."'-]+)*\s*=`)
+	yamlKeyLine   = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s#:=][^:=]*?)\s*:(\s|$)`)
+)
+
+// sniffFormat 根据内容判断配置格式，无法判断时返回空字符串
+func sniffFormat(content []byte) string {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) == 0 {
+		return "yaml"
+	}
+	if (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		return "json"
+	}
+	if trimmed[0] == '<' {
+		return "xml"
+	}
+
+	for _, line := range bytes.Split(trimmed, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		switch {
+		case tomlTableLine.Match(line), tomlKeyLine.Match(line):
+			return "toml"
+		case bytes.Equal(line, []byte("---")), bytes.HasPrefix(line, []byte("- ")), yamlKeyLine.Match(line):
+			return "yaml"
+		}
+		return ""
+	}
+	return "yaml"
+}
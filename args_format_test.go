@@ -0,0 +1,73 @@
+package args
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_SniffFormat(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"", "yaml"},
+		{`{"a": 1}`, "json"},
+		{`[1, 2]`, "json"},
+		{"# comment\n[inner]\nname = 1", "toml"},
+		{"[[servers]]\nhost = 'a'", "toml"},
+		{"a.b = 1", "toml"},
+		{"---\na: 1", "yaml"},
+		{"a: b=c", "yaml"},
+		{"- a\n- b", "yaml"},
+		{"{a:c}", ""},
+		{"%% not a config file", ""},
+		{"<?xml version=\"1.0\"?>\n<config/>", "xml"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, sniffFormat([]byte(tt.content)), tt.content)
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	formatRegistry.Lock()
+	formats := append([]*Format{}, formatRegistry.formats...)
+	formatRegistry.Unlock()
+	t.Cleanup(func() {
+		formatRegistry.Lock()
+		formatRegistry.formats = formats
+		formatRegistry.Unlock()
+	})
+
+	RegisterFormat("kv", []string{"kv"}, func(data []byte, v interface{}) error {
+		m := map[string]interface{}{}
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			kv := strings.SplitN(line, "=", 2)
+			m[kv[0]] = kv[1]
+		}
+		*(v.(*map[string]interface{})) = m
+		return nil
+	}, nil)
+
+	format, found := LookupFormat("kv")
+	assert.True(t, found)
+	assert.Equal(t, "kv", format.Name)
+	assert.Equal(t, "文件类型不支持。仅支持：【json/yml/yaml/toml/ini/properties/hcl/xml/kv】", ErrFileType.Error())
+
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test.kv"}
+	err := New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "kv-name", testCfg.Name)
+	assert.Equal(t, 8, testCfg.Arg)
+
+	format, found = LookupFormat("yml")
+	assert.True(t, found)
+	assert.Equal(t, "yaml", format.Name)
+	_, found = LookupFormat("xxx")
+	assert.False(t, found)
+}
+
+func Test_FileTypeError(t *testing.T) {
+	assert.Equal(t, "文件类型不支持。仅支持：【json/yml/yaml/toml/ini/properties/hcl/xml】", ErrFileType.Error())
+}
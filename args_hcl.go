@@ -0,0 +1,431 @@
+package args
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// hclUnmarshal 解析 HCL 格式配置，仅支持其中的静态数据子集
+//
+// 支持属性（name = "value"）、块（inner { ... }，带标签的块 server "web" { ... } 映射为 server.web）、
+// 字符串、数字、布尔、列表、对象、heredoc 以及 #、// 与 /* */ 注释。
+// 同名的块重复出现时解析为列表。
+// 不支持字符串插值（"${var.x}"）、函数调用、变量引用、运算符、条件及 for 表达式等 HCL2 表达式，
+// 遇到时返回“不支持的表达式”错误，"${...}" 在字符串中按原文保留
+func hclUnmarshal(data []byte, v interface{}) error {
+	tree, err := parseHCL(string(data))
+	if err != nil {
+		return err
+	}
+	return setDecoded(v, tree)
+}
+
+type hclParser struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+func parseHCL(content string) (map[string]interface{}, error) {
+	p := &hclParser{src: []rune(strings.TrimPrefix(content, "\ufeff")), line: 1}
+	return p.parseBody(false)
+}
+
+func (p *hclParser) errorf(format string, args ...interface{}) error {
+	return &syntaxError{p.line, fmt.Sprintf(format, args...)}
+}
+
+func (p *hclParser) peek() rune {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *hclParser) peekAt(offset int) rune {
+	if p.pos+offset >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos+offset]
+}
+
+func (p *hclParser) next() rune {
+	c := p.peek()
+	if c != 0 {
+		p.pos++
+		if c == '\n' {
+			p.line++
+		}
+	}
+	return c
+}
+
+// skip 跳过空白与注释，newlines 为 false 时停在换行符之前
+func (p *hclParser) skip(newlines bool) error {
+	for {
+		c := p.peek()
+		switch {
+		case c == '\n':
+			if !newlines {
+				return nil
+			}
+			p.next()
+		case c == ' ' || c == '\t' || c == '\r':
+			p.next()
+		case c == '#' || (c == '/' && p.peekAt(1) == '/'):
+			for p.peek() != '\n' && p.peek() != 0 {
+				p.next()
+			}
+		case c == '/' && p.peekAt(1) == '*':
+			line := p.line
+			p.next()
+			p.next()
+			for !(p.peek() == '*' && p.peekAt(1) == '/') {
+				if p.next() == 0 {
+					return &syntaxError{line, "注释未闭合"}
+				}
+			}
+			p.next()
+			p.next()
+		default:
+			return nil
+		}
+	}
+}
+
+func (p *hclParser) parseBody(inBlock bool) (map[string]interface{}, error) {
+	body := map[string]interface{}{}
+	for {
+		if err := p.skip(true); err != nil {
+			return nil, err
+		}
+		switch p.peek() {
+		case 0:
+			if inBlock {
+				return nil, p.errorf("块缺少 '}'")
+			}
+			return body, nil
+		case '}':
+			if !inBlock {
+				return nil, p.errorf("多余的 '}'")
+			}
+			p.next()
+			return body, nil
+		}
+
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.skip(false); err != nil {
+			return nil, err
+		}
+
+		if p.peek() == '=' {
+			p.next()
+			if _, found := body[key]; found {
+				return nil, p.errorf("重复的属性 %s", key)
+			}
+			if err := p.skip(false); err != nil {
+				return nil, err
+			}
+			value, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			body[key] = value
+			if err := p.skip(false); err != nil {
+				return nil, err
+			}
+			if c := p.peek(); c != '\n' && c != 0 && !(inBlock && c == '}') {
+				return nil, p.errorf("属性 %s 后存在多余内容 %q", key, c)
+			}
+			continue
+		}
+
+		line := p.line
+		var labels []string
+		for p.peek() != '{' {
+			if p.peek() == '\n' || p.peek() == 0 {
+				return nil, p.errorf("%s 后期望 '=' 或 '{'", key)
+			}
+			label, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			labels = append(labels, label)
+			if err := p.skip(false); err != nil {
+				return nil, err
+			}
+		}
+		p.next()
+		block, err := p.parseBody(true)
+		if err != nil {
+			return nil, err
+		}
+		if err := insertHCLBlock(body, append([]string{key}, labels...), block); err != nil {
+			return nil, &syntaxError{line, err.Error()}
+		}
+	}
+}
+
+// insertHCLBlock 将块写入 body，标签作为嵌套键
+func insertHCLBlock(body map[string]interface{}, path []string, block map[string]interface{}) error {
+	m := body
+	for _, part := range path[:len(path)-1] {
+		switch child := m[part].(type) {
+		case nil:
+			next := map[string]interface{}{}
+			m[part] = next
+			m = next
+		case map[string]interface{}:
+			m = child
+		default:
+			return fmt.Errorf("块 %s 与同名属性冲突", strings.Join(path, "."))
+		}
+	}
+
+	last := path[len(path)-1]
+	switch existing := m[last].(type) {
+	case nil:
+		m[last] = block
+	case map[string]interface{}:
+		m[last] = []interface{}{existing, block}
+	case []interface{}:
+		m[last] = append(existing, block)
+	default:
+		return fmt.Errorf("块 %s 与同名属性冲突", strings.Join(path, "."))
+	}
+	return nil
+}
+
+// parseKey 解析标识符或带引号的字符串
+func (p *hclParser) parseKey() (string, error) {
+	if p.peek() == '"' {
+		return p.parseString()
+	}
+	start := p.pos
+	for c := p.peek(); c == '_' || c == '-' || c == '.' || unicode.IsLetter(c) || unicode.IsDigit(c); c = p.peek() {
+		p.next()
+	}
+	if start == p.pos {
+		return "", p.errorf("期望名称，得到 %q", p.peek())
+	}
+	return string(p.src[start:p.pos]), nil
+}
+
+func (p *hclParser) parseExpr() (interface{}, error) {
+	c := p.peek()
+	switch {
+	case c == '"':
+		return p.parseString()
+	case c == '<' && p.peekAt(1) == '<':
+		return p.parseHeredoc()
+	case c == '[':
+		return p.parseList()
+	case c == '{':
+		return p.parseObject()
+	case c == '-' || c == '+' || unicode.IsDigit(c):
+		return p.parseNumber()
+	case unicode.IsLetter(c):
+		word, _ := p.parseKey()
+		switch word {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return nil, p.errorf("不支持的表达式 %s", word)
+	case c == 0 || c == '\n':
+		return nil, p.errorf("缺少值")
+	}
+	return nil, p.errorf("不支持的表达式 %q", c)
+}
+
+func (p *hclParser) parseString() (string, error) {
+	line := p.line
+	p.next()
+	var b strings.Builder
+	for {
+		c := p.next()
+		switch c {
+		case 0, '\n':
+			return "", &syntaxError{line, "字符串缺少结束引号"}
+		case '"':
+			return b.String(), nil
+		case '\\':
+			e := p.next()
+			switch e {
+			case 'n':
+				b.WriteRune('\n')
+			case 'r':
+				b.WriteRune('\r')
+			case 't':
+				b.WriteRune('\t')
+			case '"', '\\':
+				b.WriteRune(e)
+			case 'u':
+				if p.pos+4 > len(p.src) {
+					return "", p.errorf("无效的 unicode 转义")
+				}
+				r, err := strconv.ParseUint(string(p.src[p.pos:p.pos+4]), 16, 32)
+				if err != nil {
+					return "", p.errorf("无效的 unicode 转义 %q", string(p.src[p.pos:p.pos+4]))
+				}
+				p.pos += 4
+				b.WriteRune(rune(r))
+			default:
+				return "", p.errorf("无效的转义字符 \\%c", e)
+			}
+		default:
+			b.WriteRune(c)
+		}
+	}
+}
+
+// parseHeredoc 解析 <<EOF 与 <<-EOF（去掉公共缩进）形式的多行字符串
+func (p *hclParser) parseHeredoc() (string, error) {
+	line := p.line
+	p.next()
+	p.next()
+	indent := false
+	if p.peek() == '-' {
+		indent = true
+		p.next()
+	}
+	start := p.pos
+	for p.peek() != '\n' && p.peek() != 0 {
+		p.next()
+	}
+	marker := strings.TrimSpace(string(p.src[start:p.pos]))
+	if marker == "" {
+		return "", p.errorf("heredoc 缺少结束标记名")
+	}
+	p.next()
+
+	var lines []string
+	for {
+		if p.peek() == 0 {
+			return "", &syntaxError{line, fmt.Sprintf("heredoc 缺少结束标记 %s", marker)}
+		}
+		start := p.pos
+		for p.peek() != '\n' && p.peek() != 0 {
+			p.next()
+		}
+		text := strings.TrimRight(string(p.src[start:p.pos]), "\r")
+		if strings.TrimSpace(text) == marker {
+			break
+		}
+		p.next()
+		lines = append(lines, text)
+	}
+
+	if indent {
+		min := -1
+		for _, l := range lines {
+			if strings.TrimSpace(l) == "" {
+				continue
+			}
+			n := len(l) - len(strings.TrimLeft(l, " \t"))
+			if min < 0 || n < min {
+				min = n
+			}
+		}
+		for i, l := range lines {
+			if len(l) >= min && min > 0 {
+				lines[i] = l[min:]
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+func (p *hclParser) parseNumber() (interface{}, error) {
+	start := p.pos
+	for c := p.peek(); c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E' || unicode.IsDigit(c); c = p.peek() {
+		p.next()
+	}
+	text := string(p.src[start:p.pos])
+	if !strings.ContainsAny(text, ".eE") {
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return i, nil
+		}
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, p.errorf("无效的数字 %s", text)
+	}
+	return f, nil
+}
+
+func (p *hclParser) parseList() ([]interface{}, error) {
+	p.next()
+	list := []interface{}{}
+	for {
+		if err := p.skip(true); err != nil {
+			return nil, err
+		}
+		if p.peek() == ']' {
+			p.next()
+			return list, nil
+		}
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+		if err := p.skip(true); err != nil {
+			return nil, err
+		}
+		switch p.peek() {
+		case ',':
+			p.next()
+		case ']':
+		default:
+			return nil, p.errorf("列表中期望 ',' 或 ']'，得到 %q", p.peek())
+		}
+	}
+}
+
+func (p *hclParser) parseObject() (map[string]interface{}, error) {
+	p.next()
+	obj := map[string]interface{}{}
+	for {
+		if err := p.skip(true); err != nil {
+			return nil, err
+		}
+		if p.peek() == '}' {
+			p.next()
+			return obj, nil
+		}
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.skip(false); err != nil {
+			return nil, err
+		}
+		if c := p.next(); c != '=' && c != ':' {
+			return nil, p.errorf("对象中 %s 后期望 '=' 或 ':'", key)
+		}
+		if err := p.skip(false); err != nil {
+			return nil, err
+		}
+		if obj[key], err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		if err := p.skip(false); err != nil {
+			return nil, err
+		}
+		if p.peek() == ',' {
+			p.next()
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package args
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_ParseHCL(t *testing.T) {
+	tree, err := parseHCL(`
+/* block comment */
+enabled = true
+ratio   = 1.5
+server "web" {
+  port = 80
+}
+server "api" {
+  port = -1
+}
+listener {
+  port = 1
+}
+listener {
+  port = 2
+}
+text = <<-EOT
+    indented
+      more
+    EOT
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"enabled": true,
+		"ratio":   1.5,
+		"server": map[string]interface{}{
+			"web": map[string]interface{}{"port": int64(80)},
+			"api": map[string]interface{}{"port": int64(-1)},
+		},
+		"listener": []interface{}{
+			map[string]interface{}{"port": int64(1)},
+			map[string]interface{}{"port": int64(2)},
+		},
+		"text": "indented\n  more\n",
+	}, tree)
+
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{"a = 1\nb = \"open\n", "line 2: 字符串缺少结束引号"},
+		{"a = 1\na = 2\n", "line 2: 重复的属性 a"},
+		{"block {\n  a = 1\n", "line 3: 块缺少 '}'"},
+		{"a = 1 2\n", "line 1: 属性 a 后存在多余内容 '2'"},
+		{"a = [1 2]\n", "line 1: 列表中期望 ',' 或 ']'，得到 '2'"},
+		{"a\n", "line 1: a 后期望 '=' 或 '{'"},
+		// 不支持的 HCL2 表达式
+		{"a = var.x\n", "line 1: 不支持的表达式 var.x"},
+		{"a = upper(\"x\")\n", "line 1: 不支持的表达式 upper"},
+	}
+	for _, tt := range tests {
+		_, err := parseHCL(tt.content)
+		if assert.NotNil(t, err, tt.content) {
+			assert.Equal(t, tt.err, err.Error())
+		}
+	}
+}
+
+func Test_ConfigFileHclFormat(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test.hcl"}
+	err := New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "test-hcl-name", testCfg.Name)
+	assert.Equal(t, 77, testCfg.Arg)
+	assert.Equal(t, "test-hcl-inner-name", testCfg.InnerArg.Name)
+	assert.Equal(t, 777, testCfg.InnerArg.Arg)
+	assert.Equal(t, []string{"a", "b"}, testCfg.InnerArg.Array)
+	assert.Equal(t, map[string]string{"k": "v"}, testCfg.InnerArg.Map)
+}
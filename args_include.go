@@ -0,0 +1,124 @@
+package args
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInclude 配置文件包含错误
+var ErrInclude = errors.New("配置文件包含错误")
+
+// loadConfigFile 读取配置文件及其包含的文件，并合并其中激活的 profile 段
+func (a *AppArgs) loadConfigFile(set *flag.FlagSet, filePath, format string, chain []string) (map[string]interface{}, error) {
+	tree, err := a.readConfigFile(set, filePath, format)
+	if err != nil {
+		return nil, err
+	}
+	// 文件自身的键优先于其包含的文件，在包含的文件之后记录
+	own := deepCopy(tree).(map[string]interface{})
+	a.applyProfileSections(own)
+	if a.CfgIncludeKey != "" {
+		if tree, err = a.mergeIncludes(set, filePath, tree, chain); err != nil {
+			return nil, err
+		}
+	}
+	a.applyProfileSections(tree)
+	a.keySources = append(a.keySources, keySource{path: filePath, keys: treeKeys(own, "", map[string]bool{})})
+	return tree, nil
+}
+
+// mergeIncludes 读取 tree 中包含的文件并合并。被包含的文件按顺序合并作为基础，当前文件中的值优先；
+// 被包含文件的相对路径相对于当前文件所在目录，格式根据各自的扩展名或内容判断
+func (a *AppArgs) mergeIncludes(set *flag.FlagSet, filePath string, tree map[string]interface{}, chain []string) (map[string]interface{}, error) {
+	value, found := tree[a.CfgIncludeKey]
+	if !found {
+		return tree, nil
+	}
+	delete(tree, a.CfgIncludeKey)
+	includes, err := includePaths(value)
+	if err != nil {
+		return nil, &FileError{Kind: ErrInclude, Path: filePath, Cause: err}
+	}
+
+	chain = append(chain, absPath(filePath))
+	for i, include := range includes {
+		if !filepath.IsAbs(include) {
+			includes[i] = filepath.Join(a.includeDir(filePath), include)
+		}
+	}
+	files, err := a.expandConfigDirs(set, includes)
+	if err != nil {
+		return nil, &FileError{Kind: ErrInclude, Path: filePath, Cause: err}
+	}
+
+	base := map[string]interface{}{}
+	for _, include := range files {
+		if isProfileOverlay(files, include, a.profiles) {
+			continue
+		}
+		if cycle := includeCycle(chain, absPath(include)); cycle != "" {
+			return nil, &FileError{Kind: ErrInclude, Path: filePath, Cause: fmt.Errorf("循环包含 %s", cycle)}
+		}
+		a.cfgFiles = append(a.cfgFiles, include)
+		includeTree, err := a.loadWithOverlays(set, include, "", chain)
+		if err != nil {
+			return nil, &FileError{Kind: ErrInclude, Path: filePath, Cause: err}
+		}
+		mergeTree(base, includeTree, a.SliceMerge)
+	}
+	mergeTree(base, tree, a.SliceMerge)
+	return base, nil
+}
+
+// includePaths 包含指令的值：字符串（可用逗号分隔多个路径）或字符串列表
+func includePaths(value interface{}) ([]string, error) {
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	default:
+		items = []interface{}{v}
+	}
+
+	var paths []string
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("无效的包含路径 %v", item)
+		}
+		for _, p := range strings.Split(s, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// includeDir 被包含文件相对路径的基准目录，标准输入以工作目录为基准
+func (a *AppArgs) includeDir(filePath string) string {
+	if filePath == "-" {
+		return a.WorkDir
+	}
+	return filepath.Dir(filePath)
+}
+
+// includeCycle 文件已在包含链中时返回循环路径
+func includeCycle(chain []string, filePath string) string {
+	for i, p := range chain {
+		if p == filePath {
+			return strings.Join(append(chain[i:len(chain):len(chain)], filePath), " -> ")
+		}
+	}
+	return ""
+}
+
+func absPath(filePath string) string {
+	if abs, err := filepath.Abs(filePath); err == nil {
+		return abs
+	}
+	return filePath
+}
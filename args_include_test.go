@@ -0,0 +1,52 @@
+package args
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIncludes(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/include/main.yaml"}
+	appArgs := New(args[0], Store(testCfg), Strict(), Includes("include"), FileConfigEnabled("config", "", true, ""))
+	err := appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, &TestArg1{
+		Name: "main",
+		Arg:  5,
+		InnerArg: &TestInnerArg{
+			Name: "main-inner",
+			Arg:  2,
+			Age:  4,
+		},
+	}, testCfg)
+	assert.Equal(t, []string{
+		"test_data/include/main.yaml",
+		"test_data/include/common.toml",
+		"test_data/include/extra.json",
+		"test_data/include/nested/deep.ini",
+	}, appArgs.ConfigFiles())
+}
+
+func TestIncludesError(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/include/cycle-a.yaml"}
+	err := New(args[0], Store(testCfg), Includes("include"), FileConfigEnabled("config", "", false, "")).Run(args)
+	assert.True(t, errors.Is(err, ErrInclude))
+	assert.Contains(t, err.Error(), "循环包含")
+	assert.Contains(t, err.Error(), "cycle-a.yaml -> ")
+
+	args = []string{"test-app", "-config=test_data/include/broken.yaml"}
+	err = New(args[0], Store(testCfg), Includes("include"), FileConfigEnabled("config", "", false, "")).Run(args)
+	assert.True(t, errors.Is(err, ErrInclude))
+	assert.True(t, errors.Is(err, ErrFileNotFound))
+
+	// 未启用时 include 只是普通的未知键
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	args = []string{"test-app", "-config=test_data/include/main.yaml"}
+	err = New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "main", testCfg.Name)
+	assert.Equal(t, 0, testCfg.Arg)
+}
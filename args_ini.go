@@ -0,0 +1,132 @@
+package args
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// iniUnmarshal 解析 INI 格式配置
+//
+// 节名映射为嵌套键（[inner] 下的 name 对应 inner.name，[a.b] 对应 a.b），
+// 支持 ; 与 # 注释、带引号或不带引号的值、key = value 与 key: value，
+// 同一节内重复出现的键解析为切片，重复出现的节合并
+func iniUnmarshal(data []byte, v interface{}) error {
+	tree, err := parseINI(string(data))
+	if err != nil {
+		return err
+	}
+	return setDecoded(v, tree)
+}
+
+// syntaxError 带行号的配置文件语法错误
+type syntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+func parseINI(content string) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	section := root
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lineNo := i + 1
+		line = strings.TrimSpace(line)
+		if i == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, &syntaxError{lineNo, "节名缺少 ']'"}
+			}
+			if rest := strings.TrimSpace(line[end+1:]); rest != "" && rest[0] != ';' && rest[0] != '#' {
+				return nil, &syntaxError{lineNo, fmt.Sprintf("节名后存在多余内容 %q", rest)}
+			}
+			name := strings.TrimSpace(line[1:end])
+			if name == "" {
+				return nil, &syntaxError{lineNo, "节名为空"}
+			}
+			section = root
+			for _, part := range strings.Split(name, ".") {
+				part = strings.TrimSpace(part)
+				child, ok := section[part].(map[string]interface{})
+				if !ok {
+					if _, found := section[part]; found {
+						return nil, &syntaxError{lineNo, fmt.Sprintf("节 [%s] 与同名键冲突", name)}
+					}
+					child = map[string]interface{}{}
+					section[part] = child
+				}
+				section = child
+			}
+			continue
+		}
+
+		idx := strings.IndexAny(line, "=:")
+		if idx <= 0 {
+			return nil, &syntaxError{lineNo, fmt.Sprintf("无效的键值 %q", line)}
+		}
+		key := strings.TrimSpace(line[:idx])
+		value, err := iniValue(strings.TrimSpace(line[idx+1:]))
+		if err != nil {
+			return nil, &syntaxError{lineNo, err.Error()}
+		}
+
+		switch existing := section[key].(type) {
+		case nil:
+			section[key] = value
+		case []interface{}:
+			section[key] = append(existing, value)
+		case map[string]interface{}:
+			return nil, &syntaxError{lineNo, fmt.Sprintf("键 %s 与同名节冲突", key)}
+		default:
+			section[key] = []interface{}{existing, value}
+		}
+	}
+	return root, nil
+}
+
+// iniValue 解析值：引号内的值原样保留（双引号支持转义），未加引号的值去掉行内注释
+func iniValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if quote := value[0]; quote == '"' || quote == '\'' {
+		end := closingQuote(value[1:], quote)
+		if end < 0 {
+			return "", fmt.Errorf("引号未闭合")
+		}
+		rest := strings.TrimSpace(value[end+2:])
+		if rest != "" && rest[0] != ';' && rest[0] != '#' {
+			return "", fmt.Errorf("引号后存在多余内容 %q", rest)
+		}
+		if quote == '"' {
+			return unescapeDoubleQuoted(value[1 : end+1]), nil
+		}
+		return value[1 : end+1], nil
+	}
+	for i := 1; i < len(value); i++ {
+		if (value[i] == ';' || value[i] == '#') && (value[i-1] == ' ' || value[i-1] == '\t') {
+			return strings.TrimSpace(value[:i]), nil
+		}
+	}
+	return value, nil
+}
+
+// setDecoded 将解析得到的配置树写入 v，v 为 *map[string]interface{} 时直接赋值
+func setDecoded(v interface{}, tree map[string]interface{}) error {
+	if m, ok := v.(*map[string]interface{}); ok {
+		*m = tree
+		return nil
+	}
+	return assignTree(reflect.ValueOf(v), tree, "")
+}
@@ -0,0 +1,49 @@
+package args
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_ParseINI(t *testing.T) {
+	tree, err := parseINI(`
+top = 1
+[a.b]
+x = 'single # kept'
+y = "tab\tvalue"
+[a]
+z = plain value # comment
+z = second
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"top": "1",
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{"x": "single # kept", "y": "tab\tvalue"},
+			"z": []interface{}{"plain value", "second"},
+		},
+	}, tree)
+
+	_, err = parseINI("a = 1\n[broken\n")
+	assert.Equal(t, "line 2: 节名缺少 ']'", err.Error())
+	_, err = parseINI("no value\n")
+	assert.Equal(t, `line 1: 无效的键值 "no value"`, err.Error())
+	_, err = parseINI("a = \"open\n")
+	assert.Equal(t, "line 1: 引号未闭合", err.Error())
+}
+
+func Test_ConfigFileIniFormat(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test.ini"}
+	appArgs := New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, ""))
+
+	err := appArgs.Run(args)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "test-ini-name", testCfg.Name)
+	assert.Equal(t, 55, testCfg.Arg)
+	assert.Equal(t, "quoted ; value", testCfg.InnerArg.Name)
+	assert.Equal(t, 555, testCfg.InnerArg.Arg)
+	assert.Equal(t, uint8(5), testCfg.InnerArg.Age)
+	assert.Equal(t, []string{"a", "b"}, testCfg.InnerArg.Array)
+}
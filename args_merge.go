@@ -0,0 +1,549 @@
+package args
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// SlicePolicy 多个配置文件合并时切片的合并策略
+type SlicePolicy int
+
+const (
+	// SliceReplace 后加载文件中的切片替换先加载的切片
+	SliceReplace SlicePolicy = iota
+	// SliceAppend 后加载文件中的切片追加到先加载的切片之后
+	SliceAppend
+)
+
+// normalizeTree 将各格式解码得到的数据统一为 map[string]interface{} 与 []interface{}
+func normalizeTree(data interface{}) interface{} {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		for k, v := range d {
+			d[k] = normalizeTree(v)
+		}
+		return d
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(d))
+		for k, v := range d {
+			m[fmt.Sprintf("%v", k)] = normalizeTree(v)
+		}
+		return m
+	case []interface{}:
+		for i, v := range d {
+			d[i] = normalizeTree(v)
+		}
+		return d
+	case []map[string]interface{}:
+		s := make([]interface{}, len(d))
+		for i, v := range d {
+			s[i] = normalizeTree(v)
+		}
+		return s
+	case json.Number:
+		if i, err := d.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(string(d), 10, 64); err == nil {
+			return u
+		}
+		if f, err := d.Float64(); err == nil {
+			return f
+		}
+		return string(d)
+	default:
+		return data
+	}
+}
+
+// mergeTree 将 src 深度合并到 dst：map 按键合并，切片按 policy 替换或追加，其他值直接覆盖
+func mergeTree(dst, src map[string]interface{}, policy SlicePolicy) {
+	for k, sv := range src {
+		dv, found := dst[k]
+		if !found {
+			dst[k] = sv
+			continue
+		}
+		if dm, ok := dv.(map[string]interface{}); ok {
+			if sm, ok := sv.(map[string]interface{}); ok {
+				mergeTree(dm, sm, policy)
+				continue
+			}
+		}
+		if ds, ok := dv.([]interface{}); ok && policy == SliceAppend {
+			if ss, ok := sv.([]interface{}); ok {
+				dst[k] = append(ds, ss...)
+				continue
+			}
+		}
+		dst[k] = sv
+	}
+}
+
+// assignTree 按照 Bean2Args 相同的 tag 名称规则将配置数据写入对象，未出现的键保持原值。
+// 无法转换的值不中断写入，所有错误合并为 MultiError 返回
+func assignTree(v reflect.Value, data interface{}, path string) error {
+	if data == nil {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+
+	scalar, isScalar := data.(yamlScalar)
+	if isScalar && v.Kind() != reflect.Ptr {
+		// YAML 标量写入字符串或按文本解码的类型时使用原文，其他类型使用解析后的值
+		data = scalar.value
+		if v.Kind() == reflect.String || (v.CanAddr() && isTextUnmarshaler(v.Addr())) {
+			data = scalar.text
+		}
+	}
+
+	if v.CanAddr() && !reflect.TypeOf(data).AssignableTo(v.Type()) {
+		// 自定义解码的类型：配置数据重新编码后交给其自身的解码方法
+		switch u := v.Addr().Interface().(type) {
+		case yaml.Unmarshaler:
+			return wrapAssignError(path, data, u.UnmarshalYAML(func(out interface{}) error {
+				if isScalar {
+					return yaml.Unmarshal([]byte(scalar.text), out)
+				}
+				raw, err := yaml.Marshal(data)
+				if err != nil {
+					return err
+				}
+				return yaml.Unmarshal(raw, out)
+			}))
+		case json.Unmarshaler:
+			raw, err := json.Marshal(data)
+			if err != nil {
+				return wrapAssignError(path, data, err)
+			}
+			return wrapAssignError(path, data, u.UnmarshalJSON(raw))
+		case encoding.TextUnmarshaler:
+			if s, ok := data.(string); ok {
+				return wrapAssignError(path, data, u.UnmarshalText([]byte(s)))
+			}
+		}
+	}
+	if dv := reflect.ValueOf(data); dv.Type().AssignableTo(v.Type()) && v.Kind() != reflect.Map && v.Kind() != reflect.Slice {
+		v.Set(dv)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return assignTree(v.Elem(), data, path)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return assignTypeError(path, data, v)
+		}
+		v.Set(reflect.ValueOf(data))
+		return nil
+	case reflect.Struct:
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return assignTypeError(path, data, v)
+		}
+		return assignStruct(v, m, path)
+	case reflect.Map:
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return assignTypeError(path, data, v)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var errs MultiError
+		for _, key := range keys {
+			item := m[key]
+			mapKey := reflect.New(v.Type().Key()).Elem()
+			if err := assignScalar(mapKey, key, joinPath(path, key)); err != nil {
+				errs = appendError(errs, err)
+				continue
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := assignTree(elem, item, joinPath(path, key)); err != nil {
+				errs = appendError(errs, err)
+				continue
+			}
+			v.SetMapIndex(mapKey, elem)
+		}
+		return errs.errorOrNil()
+	case reflect.Slice, reflect.Array:
+		items, ok := data.([]interface{})
+		if !ok {
+			// 单个值视为只有一个元素的切片
+			items = []interface{}{data}
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+		}
+		var errs MultiError
+		for i, item := range items {
+			if i >= v.Len() {
+				break
+			}
+			errs = appendError(errs, assignTree(v.Index(i), item, joinPath(path, strconv.Itoa(i))))
+		}
+		return errs.errorOrNil()
+	default:
+		return assignScalar(v, data, path)
+	}
+}
+
+func isTextUnmarshaler(v reflect.Value) bool {
+	_, ok := v.Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+// fieldTreeName 字段在配置树中的键名，与直接解码到结构体的规则一致：优先使用 tag 中的名称，
+// tag 只有选项（如 yaml:",omitempty"）或没有 tag 的导出字段使用字段名（匹配时忽略大小写）。
+// inline 为 true 时字段展开到当前层级：无名称的嵌入结构体或 yaml:",inline" 的结构体。
+// ok 为 false 时字段不对应任何键，如 tag 为 "-" 或未导出的字段
+func fieldTreeName(field reflect.StructField) (name string, inline, ok bool) {
+	if field.PkgPath != "" && !field.Anonymous {
+		return "", false, false
+	}
+	if name, found := tagArgName(field); found {
+		return name, false, true
+	}
+	isStruct := realType(field.Type).Kind() == reflect.Struct
+	for _, key := range argTagKeys {
+		tag, found := field.Tag.Lookup(key)
+		if !found {
+			continue
+		}
+		options := strings.Split(tag, ",")
+		if options[0] == "-" {
+			return "", false, false
+		}
+		for _, option := range options[1:] {
+			if option == "inline" && isStruct {
+				return "", true, true
+			}
+		}
+	}
+	if field.Anonymous && isStruct {
+		return "", true, true
+	}
+	if field.PkgPath != "" {
+		return "", false, false
+	}
+	return field.Name, false, true
+}
+
+// assignStruct 按字段 tag 名称写入结构体，无 tag 的导出字段按字段名（忽略大小写）匹配
+func assignStruct(v reflect.Value, m map[string]interface{}, path string) error {
+	var errs MultiError
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, inline, ok := fieldTreeName(field)
+		if !ok {
+			continue
+		}
+		if inline {
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Ptr && fv.IsNil() {
+				fv.Set(reflect.New(field.Type.Elem()))
+			}
+			errs = appendError(errs, assignStruct(reflect.Indirect(fv), m, path))
+			continue
+		}
+
+		key, found := lookupTreeKey(m, name)
+		if !found {
+			continue
+		}
+		errs = appendError(errs, assignTree(v.Field(i), m[key], joinPath(path, name)))
+	}
+	return errs.errorOrNil()
+}
+
+// canonicalTree 将按格式专用 tag（如 json:"dbHost"）命名的键改为 assignTree 使用的参数名，
+// 使不同格式的文件在合并时使用相同的键。无法对应到字段的键保持不变
+func canonicalTree(t reflect.Type, data interface{}, tagKey string) interface{} {
+	t = realType(t)
+	switch t.Kind() {
+	case reflect.Struct:
+		if m, ok := data.(map[string]interface{}); ok {
+			canonicalStruct(t, m, tagKey)
+		}
+	case reflect.Map:
+		if m, ok := data.(map[string]interface{}); ok {
+			for k, v := range m {
+				m[k] = canonicalTree(t.Elem(), v, tagKey)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if items, ok := data.([]interface{}); ok {
+			for i, v := range items {
+				items[i] = canonicalTree(t.Elem(), v, tagKey)
+			}
+		}
+	}
+	return data
+}
+
+// canonicalStruct 按 assignStruct 相同的字段规则改写结构体对应的键。
+// 先收集再统一改写，避免字段的格式专用名与另一字段的参数名相同时互相覆盖
+func canonicalStruct(t reflect.Type, m map[string]interface{}, tagKey string) {
+	renamed := map[string]interface{}{}
+	collectCanonicalKeys(t, m, tagKey, renamed)
+	for name, value := range renamed {
+		m[name] = value
+	}
+}
+
+func collectCanonicalKeys(t reflect.Type, m map[string]interface{}, tagKey string, renamed map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, inline, ok := fieldTreeName(field)
+		if !ok {
+			continue
+		}
+		if inline {
+			collectCanonicalKeys(realType(field.Type), m, tagKey, renamed)
+			continue
+		}
+
+		fileName := name
+		if tag := strings.Split(field.Tag.Get(tagKey), ",")[0]; tag != "" && tag != "-" {
+			fileName = tag
+		}
+		key, found := lookupTreeKey(m, fileName)
+		if !found {
+			continue
+		}
+		renamed[name] = canonicalTree(field.Type, m[key], tagKey)
+		delete(m, key)
+	}
+}
+
+// keySource 已加载的配置文件及其中出现的键路径（小写），按合并的先后顺序记录
+type keySource struct {
+	path string
+	keys map[string]bool
+}
+
+// treeKeys 收集配置树中所有映射键的路径，切片元素不单独记录
+func treeKeys(data interface{}, path string, keys map[string]bool) map[string]bool {
+	if m, ok := data.(map[string]interface{}); ok {
+		for k, v := range m {
+			key := joinPath(path, strings.ToLower(k))
+			keys[key] = true
+			treeKeys(v, key, keys)
+		}
+	}
+	return keys
+}
+
+// valueSource 参数值错误对应的配置文件：最后一个包含该键（或其上级键）的文件，无法确定时为全部配置文件
+func (a *AppArgs) valueSource(err error) string {
+	var valueErr *ValueError
+	if !errors.As(err, &valueErr) {
+		return a.CfgFilePath
+	}
+	for key := strings.ToLower(valueErr.Key); key != ""; {
+		for i := len(a.keySources) - 1; i >= 0; i-- {
+			if a.keySources[i].keys[key] {
+				return a.keySources[i].path
+			}
+		}
+		idx := strings.LastIndex(key, ".")
+		if idx < 0 {
+			break
+		}
+		key = key[:idx]
+	}
+	return a.CfgFilePath
+}
+
+// lookupTreeKey 查找配置键，优先精确匹配，其次忽略大小写匹配
+func lookupTreeKey(m map[string]interface{}, name string) (string, bool) {
+	if _, found := m[name]; found {
+		return name, true
+	}
+	for key := range m {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// assignScalar 写入基本类型，字符串值按目标类型解析，数值之间按目标类型转换
+func assignScalar(v reflect.Value, data interface{}, path string) error {
+	dv := reflect.ValueOf(data)
+	if s, ok := data.(string); ok {
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(strings.TrimSpace(s))
+			if err != nil {
+				return wrapAssignError(path, data, err)
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		switch v.Kind() {
+		case reflect.String:
+			v.SetString(s)
+			return nil
+		case reflect.Bool:
+			b, err := strconv.ParseBool(strings.TrimSpace(s))
+			if err != nil {
+				return wrapAssignError(path, data, err)
+			}
+			v.SetBool(b)
+			return nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i, err := strconv.ParseInt(strings.TrimSpace(s), 10, v.Type().Bits())
+			if err != nil {
+				return wrapAssignError(path, data, err)
+			}
+			v.SetInt(i)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			u, err := strconv.ParseUint(strings.TrimSpace(s), 10, v.Type().Bits())
+			if err != nil {
+				return wrapAssignError(path, data, err)
+			}
+			v.SetUint(u)
+			return nil
+		case reflect.Float32, reflect.Float64:
+			f, err := strconv.ParseFloat(strings.TrimSpace(s), v.Type().Bits())
+			if err != nil {
+				return wrapAssignError(path, data, err)
+			}
+			v.SetFloat(f)
+			return nil
+		}
+		return assignTypeError(path, data, v)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		switch dv.Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			v.SetString(fmt.Sprintf("%v", data))
+			return nil
+		}
+	case reflect.Bool:
+		if dv.Kind() == reflect.Bool {
+			v.SetBool(dv.Bool())
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if n, ok := convertNumber(dv, v.Type()); ok {
+			v.Set(n)
+			return nil
+		}
+	}
+	return assignTypeError(path, data, v)
+}
+
+// convertNumber 数值类型转换，整数目标类型不接受小数及溢出
+func convertNumber(dv reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	n := reflect.New(t).Elem()
+	switch dv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := dv.Int()
+		switch t.Kind() {
+		case reflect.Float32, reflect.Float64:
+			n.SetFloat(float64(i))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if i < 0 || n.OverflowUint(uint64(i)) {
+				return n, false
+			}
+			n.SetUint(uint64(i))
+		default:
+			if n.OverflowInt(i) {
+				return n, false
+			}
+			n.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u := dv.Uint()
+		switch t.Kind() {
+		case reflect.Float32, reflect.Float64:
+			n.SetFloat(float64(u))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if n.OverflowUint(u) {
+				return n, false
+			}
+			n.SetUint(u)
+		default:
+			if int64(u) < 0 || n.OverflowInt(int64(u)) {
+				return n, false
+			}
+			n.SetInt(int64(u))
+		}
+	case reflect.Float32, reflect.Float64:
+		f := dv.Float()
+		switch t.Kind() {
+		case reflect.Float32, reflect.Float64:
+			n.SetFloat(f)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if f < 0 || f != float64(uint64(f)) || n.OverflowUint(uint64(f)) {
+				return n, false
+			}
+			n.SetUint(uint64(f))
+		default:
+			if f != float64(int64(f)) || n.OverflowInt(int64(f)) {
+				return n, false
+			}
+			n.SetInt(int64(f))
+		}
+	default:
+		return n, false
+	}
+	return n, true
+}
+
+func assignTypeError(path string, data interface{}, v reflect.Value) error {
+	return &ValueError{Key: path, Source: "file", Value: data, Cause: fmt.Errorf("无法将 %T 类型的值转换为 %s", data, v.Type())}
+}
+
+func wrapAssignError(path string, data interface{}, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ValueError{Key: path, Source: "file", Value: data, Cause: err}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// realType 得到指针类型的真实类型
+func realType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,181 @@
+package args
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_MergeTree(t *testing.T) {
+	dst := map[string]interface{}{
+		"a": 1,
+		"m": map[string]interface{}{"x": 1, "y": 2},
+		"s": []interface{}{1, 2},
+	}
+	src := map[string]interface{}{
+		"b": 2,
+		"m": map[string]interface{}{"y": 3, "z": 4},
+		"s": []interface{}{3},
+	}
+	mergeTree(dst, src, SliceReplace)
+	assert.Equal(t, map[string]interface{}{
+		"a": 1,
+		"b": 2,
+		"m": map[string]interface{}{"x": 1, "y": 3, "z": 4},
+		"s": []interface{}{3},
+	}, dst)
+
+	mergeTree(dst, map[string]interface{}{"s": []interface{}{4}}, SliceAppend)
+	assert.Equal(t, []interface{}{3, 4}, dst["s"])
+}
+
+func Test_AssignTree(t *testing.T) {
+	testCfg := &TestArg1{Name: "keep", InnerArg: &TestInnerArg{}}
+	err := assignTree(reflect.ValueOf(testCfg), map[string]interface{}{
+		"arg":    int64(5),
+		"Ignore": 3.0,
+		"inner": map[string]interface{}{
+			"name":  12,
+			"age":   "7",
+			"array": "one",
+			"map":   map[string]interface{}{"k": "v"},
+		},
+	}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, &TestArg1{
+		Name:   "keep",
+		Arg:    5,
+		Ignore: 3,
+		InnerArg: &TestInnerArg{
+			Name:  "12",
+			Age:   7,
+			Array: []string{"one"},
+			Map:   map[string]string{"k": "v"},
+		},
+	}, testCfg)
+
+	err = assignTree(reflect.ValueOf(testCfg), map[string]interface{}{"inner": map[string]interface{}{"age": 256}}, "")
+	assert.True(t, errors.Is(err, ErrArgValue))
+	assert.Equal(t, "参数【inner.age=256】解析错误：无法将 int 类型的值转换为 uint8", err.Error())
+	err = assignTree(reflect.ValueOf(testCfg), map[string]interface{}{"arg": 1.5}, "")
+	assert.Equal(t, "参数【arg=1.5】解析错误：无法将 float64 类型的值转换为 int", err.Error())
+}
+
+type nativeLevel int
+
+func (l *nativeLevel) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	switch s {
+	case "debug":
+		*l = 1
+	case "info":
+		*l = 2
+	default:
+		return errors.New("unknown level " + s)
+	}
+	return nil
+}
+
+type nativePoint struct {
+	X, Y int
+}
+
+func (p *nativePoint) UnmarshalJSON(data []byte) error {
+	var xy [2]int
+	if err := json.Unmarshal(data, &xy); err != nil {
+		return err
+	}
+	p.X, p.Y = xy[0], xy[1]
+	return nil
+}
+
+type nativeArg struct {
+	DBHost  string        `yaml:"db_host" json:"dbHost"`
+	ID      int64         `yaml:"id" json:"id"`
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+	Level   nativeLevel   `yaml:"level" json:"level"`
+	Point   nativePoint   `yaml:"point" json:"point"`
+}
+
+func TestNativeDecoding(t *testing.T) {
+	// json 文件使用 json tag 中的键名，大整数不丢失精度，自定义 UnmarshalJSON 生效
+	testCfg := &nativeArg{}
+	args := []string{"test-app", "-config=test_data/test-native.json"}
+	err := New(args[0], Store(testCfg), Strict(), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "json-host", testCfg.DBHost)
+	assert.Equal(t, int64(9007199254740993), testCfg.ID)
+	assert.Equal(t, nativePoint{X: 1, Y: 2}, testCfg.Point)
+
+	// yaml 文件中的 time.Duration 与自定义 UnmarshalYAML
+	testCfg = &nativeArg{}
+	args = []string{"test-app", "-config=test_data/test-native.yaml"}
+	err = New(args[0], Store(testCfg), Strict(), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "yaml-host", testCfg.DBHost)
+	assert.Equal(t, 5*time.Second, testCfg.Timeout)
+	assert.Equal(t, nativeLevel(1), testCfg.Level)
+
+	// 不同格式的文件合并时按参数名对应，后面的文件优先
+	testCfg = &nativeArg{}
+	args = []string{"test-app", "-config=test_data/test-native.json,test_data/test-native.yaml"}
+	err = New(args[0], Store(testCfg), Strict(), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, &nativeArg{DBHost: "yaml-host", ID: 9007199254740993, Timeout: 5 * time.Second, Level: 1, Point: nativePoint{X: 1, Y: 2}}, testCfg)
+}
+
+func Test_CanonicalTree(t *testing.T) {
+	type swapped struct {
+		A int `yaml:"a" json:"b"`
+		B int `yaml:"b" json:"a"`
+	}
+	tree := map[string]interface{}{"a": 1, "b": 2, "c": 3}
+	canonicalTree(reflect.TypeOf(&swapped{}), tree, "json")
+	assert.Equal(t, map[string]interface{}{"a": 2, "b": 1, "c": 3}, tree)
+}
+
+func TestYamlScalarText(t *testing.T) {
+	// 写入字符串字段时保留 YAML 标量原文，其他类型按 YAML 解析后的值写入
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test-yaml-scalar.yaml"}
+	err := New(args[0], Store(testCfg), Strict(), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.10", testCfg.Name)
+	assert.Equal(t, 8, testCfg.Arg)
+	assert.Equal(t, "NO", testCfg.InnerArg.Name)
+	assert.Equal(t, []string{"on", "007", "y"}, testCfg.InnerArg.Array)
+	assert.Equal(t, map[string]string{"k": "off"}, testCfg.InnerArg.Map)
+
+	var tree map[string]interface{}
+	assert.Nil(t, yamlUnmarshal([]byte("a: 1.10\nb: [NO]\nc: ~\n"), &tree))
+	assert.Equal(t, map[string]interface{}{
+		"a": yamlScalar{value: 1.1, text: "1.10"},
+		"b": []interface{}{yamlScalar{value: false, text: "NO"}},
+		"c": nil,
+	}, tree)
+}
+
+type InlineBase struct {
+	Host string `yaml:"host"`
+}
+
+type inlineArg struct {
+	InlineBase `yaml:",inline"`
+	Port       int  `yaml:",omitempty"`
+	Debug      bool `yaml:",omitempty" json:",omitempty"`
+}
+
+func TestYamlInline(t *testing.T) {
+	// yaml:",inline" 的结构体展开到当前层级，只有选项的 tag 按字段名匹配
+	testCfg := &inlineArg{}
+	args := []string{"test-app", "-config=test_data/test-inline.yaml"}
+	err := New(args[0], Store(testCfg), Strict(), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, &inlineArg{InlineBase: InlineBase{Host: "inline-host"}, Port: 8080, Debug: true}, testCfg)
+}
@@ -0,0 +1,140 @@
+package args
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// profileSectionKey 配置文件中按 profile 划分的配置段，如 profiles: {prod: {...}}
+const profileSectionKey = "profiles"
+
+// ActiveProfiles 最近一次 Run 或重新加载激活的 profile
+func (a *AppArgs) ActiveProfiles() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.profiles
+}
+
+// activeProfiles 命令行或环境变量指定的 profile，多个以逗号分隔，后面的优先
+func (a *AppArgs) activeProfiles(set *flag.FlagSet) []string {
+	if a.CfgProfileArg == "" {
+		return nil
+	}
+	value := set.Lookup(a.CfgProfileArg).Value.String()
+	if value == "" {
+		value, _ = a.lookupEnv(a.getEnvName(a.CfgProfileArg))
+	}
+
+	var profiles []string
+	seen := map[string]bool{}
+	for _, profile := range strings.Split(value, ",") {
+		profile = strings.TrimSpace(profile)
+		if profile == "" || seen[profile] {
+			continue
+		}
+		seen[profile] = true
+		profiles = append(profiles, profile)
+	}
+	return profiles
+}
+
+// profileFiles 配置文件对应的 profile 覆盖文件，如 config.yaml 对应 config.prod.yaml，不存在的文件被忽略
+func profileFiles(filePath string, profiles []string) []string {
+	if filePath == "-" {
+		return nil
+	}
+	var files []string
+	for _, profile := range profiles {
+		name := profileFileName(filePath, profile)
+		if info, err := os.Stat(name); err == nil && !info.IsDir() {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+// profileFileName 配置文件对应 profile 的覆盖文件名
+func profileFileName(filePath, profile string) string {
+	ext := filepath.Ext(filePath)
+	return strings.TrimSuffix(filePath, ext) + "." + profile + ext
+}
+
+// isProfileOverlay 判断文件是否为 files 中其他配置文件对应激活 profile 的覆盖文件，覆盖文件随其对应的配置文件加载
+func isProfileOverlay(files []string, filePath string, profiles []string) bool {
+	for _, file := range files {
+		for _, profile := range profiles {
+			if file != filePath && profileFileName(file, profile) == filePath {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// overlayProfile 目录中形如 <base>.<profile>.<ext> 且 <base>.<ext> 同样存在的文件对应的 profile，
+// profile 须为激活或声明的 profile。未启用 profile 或不是覆盖文件时返回空
+func (a *AppArgs) overlayProfile(name string, names map[string]bool) string {
+	if a.CfgProfileArg == "" {
+		return ""
+	}
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	idx := strings.LastIndex(stem, ".")
+	if idx <= 0 || !names[stem[:idx]+ext] {
+		return ""
+	}
+	if profile := stem[idx+1:]; containsString(a.profiles, profile) || containsString(a.CfgProfileNames, profile) {
+		return profile
+	}
+	return ""
+}
+
+// loadWithOverlays 加载配置文件，并紧随其后合并激活 profile 的覆盖文件
+func (a *AppArgs) loadWithOverlays(set *flag.FlagSet, filePath, format string, chain []string) (map[string]interface{}, error) {
+	tree, err := a.loadConfigFile(set, filePath, format, chain)
+	if err != nil {
+		return nil, err
+	}
+	for _, overlay := range profileFiles(filePath, a.profiles) {
+		if !containsString(a.cfgFiles, overlay) {
+			a.cfgFiles = append(a.cfgFiles, overlay)
+		}
+		overlayTree, err := a.loadConfigFile(set, overlay, format, chain)
+		if err != nil {
+			return nil, err
+		}
+		mergeTree(tree, overlayTree, a.SliceMerge)
+	}
+	return tree, nil
+}
+
+// applyProfileSections 将配置中激活的 profile 段按顺序合并到顶层
+func (a *AppArgs) applyProfileSections(tree map[string]interface{}) {
+	if a.CfgProfileArg == "" {
+		return
+	}
+	section, found := tree[profileSectionKey]
+	if !found {
+		return
+	}
+	delete(tree, profileSectionKey)
+	sections, _ := section.(map[string]interface{})
+	for _, profile := range a.profiles {
+		if key, found := lookupTreeKey(sections, profile); found {
+			if m, ok := sections[key].(map[string]interface{}); ok {
+				mergeTree(tree, m, a.SliceMerge)
+			}
+		}
+	}
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
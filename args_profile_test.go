@@ -0,0 +1,114 @@
+package args
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/profile/config.yaml", "-profile=prod, eu"}
+	appArgs := New(args[0], Store(testCfg), Strict(), Profiles("profile"), FileConfigEnabled("config", "", true, ""))
+	err := appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"prod", "eu"}, appArgs.ActiveProfiles())
+	assert.Equal(t, []string{
+		"test_data/profile/config.yaml",
+		"test_data/profile/config.prod.yaml",
+		"test_data/profile/config.eu.yaml",
+	}, appArgs.ConfigFiles())
+	assert.Equal(t, &TestArg1{
+		Name:     "eu",
+		Arg:      10,
+		InnerArg: &TestInnerArg{Name: "eu-inner", Arg: 20},
+	}, testCfg)
+
+	// 未激活 profile 时只加载基础配置
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	args = []string{"test-app", "-config=test_data/profile/config.yaml"}
+	appArgs = New(args[0], Store(testCfg), Strict(), Profiles("profile"), FileConfigEnabled("config", "", true, ""))
+	err = appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Nil(t, appArgs.ActiveProfiles())
+	assert.Equal(t, []string{"test_data/profile/config.yaml"}, appArgs.ConfigFiles())
+	assert.Equal(t, "base", testCfg.Name)
+	assert.Equal(t, 1, testCfg.Arg)
+}
+
+func TestProfilesEnv(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/profile/config.yaml"}
+	appArgs := New(args[0], Store(testCfg), EnvArg("app"), EnvMap(map[string]string{"APP_PROFILE": "prod"}),
+		Profiles("profile"), FileConfigEnabled("config", "", true, ""))
+	err := appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"prod"}, appArgs.ActiveProfiles())
+	assert.Equal(t, "prod", testCfg.Name)
+	assert.Equal(t, 20, testCfg.InnerArg.Arg)
+
+	// 命令行优先于环境变量
+	output := &bytes.Buffer{}
+	args = []string{"test-app", "-config=test_data/profile/config.yaml", "-profile=eu", "-h"}
+	appArgs = New(args[0], Store(testCfg), EnvArg("app"), EnvMap(map[string]string{"APP_PROFILE": "prod"}), Output(output),
+		Profiles("profile"), FileConfigEnabled("config", "", true, ""))
+	assert.Equal(t, ErrHelp, appArgs.Run(args))
+	assert.Contains(t, output.String(), "Profiles: eu")
+	assert.Contains(t, output.String(), "-profile string \t (ENV: APP_PROFILE)")
+}
+
+func TestProfilesConfigDir(t *testing.T) {
+	// 配置目录中的 profile 覆盖文件不作为普通配置文件加载，而是随对应的配置文件加载
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/profile", "-profile=prod,eu"}
+	appArgs := New(args[0], Store(testCfg), Profiles("profile"), FileConfigEnabled("config", "", true, ""))
+	assert.Nil(t, appArgs.Run(args))
+	assert.Equal(t, []string{
+		"test_data/profile/config.yaml",
+		"test_data/profile/config.prod.yaml",
+		"test_data/profile/config.eu.yaml",
+	}, appArgs.ConfigFiles())
+	assert.Equal(t, "eu", testCfg.Name)
+
+	// 声明的 profile 未激活时，其覆盖文件同样不作为普通配置文件加载
+	output := &bytes.Buffer{}
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	args = []string{"test-app", "-config=test_data/profile", "-profile=prod"}
+	appArgs = New(args[0], Store(testCfg), Output(output), Profiles("profile", "prod", "eu"), FileConfigEnabled("config", "", true, ""))
+	assert.Nil(t, appArgs.Run(args))
+	assert.Equal(t, []string{"test_data/profile/config.yaml", "test_data/profile/config.prod.yaml"}, appArgs.ConfigFiles())
+	assert.Equal(t, "prod", testCfg.Name)
+	assert.Contains(t, output.String(), "配置目录[test_data/profile]跳过未激活 profile 的文件 config.eu.yaml\n")
+
+	// 不是 profile 的 <base>.<x>.<ext> 文件作为普通配置文件加载
+	for _, opt := range []Option{Profiles("profile", "prod"), func(*AppArgs) {}} {
+		testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+		args = []string{"test-app", "-config=test_data/confd-local"}
+		appArgs = New(args[0], Store(testCfg), Output(ioutil.Discard), opt, FileConfigEnabled("config", "", true, ""))
+		assert.Nil(t, appArgs.Run(args))
+		assert.Equal(t, []string{"test_data/confd-local/app.local.yaml", "test_data/confd-local/app.yaml"}, appArgs.ConfigFiles())
+		assert.Equal(t, "app", testCfg.Name)
+		assert.Equal(t, 1, testCfg.Arg)
+	}
+
+	// 包含的配置目录使用相同的规则
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	args = []string{"test-app", "-config=test_data/profile-include.yaml", "-profile=prod"}
+	appArgs = New(args[0], Store(testCfg), Output(ioutil.Discard), Includes("include"), Profiles("profile", "eu"), FileConfigEnabled("config", "", true, ""))
+	assert.Nil(t, appArgs.Run(args))
+	assert.Equal(t, []string{
+		"test_data/profile-include.yaml",
+		"test_data/profile/config.yaml",
+		"test_data/profile/config.prod.yaml",
+	}, appArgs.ConfigFiles())
+	assert.Equal(t, "prod", testCfg.Name)
+	assert.Equal(t, 10, testCfg.Arg)
+
+	// 覆盖文件排在对应的配置文件之前时，仍在其之后加载
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	args = []string{"test-app", "-config=test_data/profile/config.prod.yaml,test_data/profile/config.yaml", "-profile=prod"}
+	appArgs = New(args[0], Store(testCfg), Profiles("profile"), FileConfigEnabled("config", "", true, ""))
+	assert.Nil(t, appArgs.Run(args))
+	assert.Equal(t, "prod", testCfg.Name)
+}
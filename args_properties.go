@@ -0,0 +1,139 @@
+package args
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// propertiesUnmarshal 解析 Java .properties 格式配置，点分隔的键映射为嵌套键（inner.name=foo）
+func propertiesUnmarshal(data []byte, v interface{}) error {
+	tree, err := parseProperties(string(data))
+	if err != nil {
+		return err
+	}
+	return setDecoded(v, tree)
+}
+
+func parseProperties(content string) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(content, "\r\n", "\n"), "\r", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if i == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		// 以奇数个 \ 结尾的行与下一行拼接
+		for continuesLine(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+
+		key, value, err := splitProperty(line)
+		if err != nil {
+			return nil, &syntaxError{lineNo, err.Error()}
+		}
+		if err := setPropertyKey(root, key, value); err != nil {
+			return nil, &syntaxError{lineNo, err.Error()}
+		}
+	}
+	return root, nil
+}
+
+func continuesLine(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitProperty 拆分键值：键以未转义的 =、: 或空白结束
+func splitProperty(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if line[i] == '=' || line[i] == ':' || line[i] == ' ' || line[i] == '\t' || line[i] == '\f' {
+			end = i
+			break
+		}
+	}
+	key, err := unescapeProperty(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	value, err := unescapeProperty(rest)
+	return key, value, err
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+4 >= len(s) {
+				return "", fmt.Errorf("无效的 unicode 转义 %q", s[i-1:])
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("无效的 unicode 转义 %q", s[i-1:i+5])
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// setPropertyKey 按点分隔的键写入嵌套 map，重复的键以后出现的为准
+func setPropertyKey(root map[string]interface{}, key, value string) error {
+	parts := strings.Split(key, ".")
+	m := root
+	for i, part := range parts[:len(parts)-1] {
+		switch child := m[part].(type) {
+		case nil:
+			next := map[string]interface{}{}
+			m[part] = next
+			m = next
+		case map[string]interface{}:
+			m = child
+		default:
+			return fmt.Errorf("键 %s 与已有的值 %s 冲突", key, strings.Join(parts[:i+1], "."))
+		}
+	}
+	last := parts[len(parts)-1]
+	if _, ok := m[last].(map[string]interface{}); ok {
+		return fmt.Errorf("键 %s 与已有的嵌套键冲突", key)
+	}
+	m[last] = value
+	return nil
+}
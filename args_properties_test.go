@@ -0,0 +1,34 @@
+package args
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_ParseProperties(t *testing.T) {
+	tree, err := parseProperties("a.b=1\na.c:two\\\n   lines\nd   spaced value\ne\\=f=g\n")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"a":   map[string]interface{}{"b": "1", "c": "twolines"},
+		"d":   "spaced value",
+		"e=f": "g",
+	}, tree)
+
+	_, err = parseProperties("a=1\n# comment\na.b=2\n")
+	assert.Equal(t, "line 3: 键 a.b 与已有的值 a 冲突", err.Error())
+	_, err = parseProperties("a=\\u12\n")
+	assert.Equal(t, `line 1: 无效的 unicode 转义 "\\u12"`, err.Error())
+}
+
+func Test_ConfigFilePropertiesFormat(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test.properties"}
+	err := New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "test-properties-name", testCfg.Name)
+	assert.Equal(t, 66, testCfg.Arg)
+	assert.Equal(t, "long value", testCfg.InnerArg.Name)
+	assert.Equal(t, 666, testCfg.InnerArg.Arg)
+	assert.Equal(t, []string{"中文"}, testCfg.InnerArg.Array)
+}
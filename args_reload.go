@@ -0,0 +1,187 @@
+package args
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+)
+
+// ErrNotRun 尚未调用 Run 时不能重新加载
+var ErrNotRun = errors.New("参数尚未解析")
+
+// Watch 每隔 interval 检查一次已加载的配置文件及 .env 文件，发生变化时重新加载
+func Watch(interval time.Duration) Option {
+	return func(args *AppArgs) {
+		args.watchInterval = interval
+	}
+}
+
+// OnChange 重新加载得到不同的配置时回调，old、new 为新旧两份参数对象
+func OnChange(fn func(old, new interface{})) Option {
+	return func(args *AppArgs) {
+		args.onChange = append(args.onChange, fn)
+	}
+}
+
+// Validate 校验解析得到的参数对象，Run 时校验失败返回错误，重新加载时校验失败保留原配置
+func Validate(fn func(cfg interface{}) error) Option {
+	return func(args *AppArgs) {
+		args.validators = append(args.validators, fn)
+	}
+}
+
+// OnReloadError 自动重新加载失败时回调，默认输出错误信息
+func OnReloadError(fn func(err error)) Option {
+	return func(args *AppArgs) {
+		args.onReloadError = fn
+	}
+}
+
+// Reload 使用 Run 时的参数重新解析到一份新的参数对象，校验通过后原子替换当前配置。
+// 失败时保留原配置并返回错误。OnChange 回调在替换完成并释放锁之后执行，回调中可以再次调用 Reload 或 Run
+func (a *AppArgs) Reload() error {
+	old, cfg, err := a.reloadConfig()
+	if err != nil || reflect.DeepEqual(old, cfg) {
+		return err
+	}
+	for _, fn := range a.onChange {
+		fn(old, cfg)
+	}
+	return nil
+}
+
+// reloadConfig 重新解析并替换当前配置，返回替换前后的参数对象
+func (a *AppArgs) reloadConfig() (interface{}, interface{}, error) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	a.mu.Lock()
+	if a.defaults == nil {
+		a.mu.Unlock()
+		return nil, nil, ErrNotRun
+	}
+	cfg := deepCopy(a.defaults)
+	err := a.resolve(cfg, a.arguments)
+	a.mu.Unlock()
+	if err == nil {
+		err = a.validate(cfg)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	old := a.current.Load()
+	a.current.Store(cfg)
+	return old, cfg, nil
+}
+
+// Current 当前生效的参数对象，Run 之前为 nil。重新加载时替换为新的对象而不修改原对象，
+// 因此可在重新加载的同时并发读取；返回的对象应视为只读
+func (a *AppArgs) Current() interface{} {
+	return a.current.Load()
+}
+
+// Snapshot 当前生效的参数对象的深拷贝，可自由修改
+func (a *AppArgs) Snapshot() interface{} {
+	return deepCopy(a.Current())
+}
+
+// Stop 停止配置文件监听及信号处理，返回时后台的重新加载均已结束
+func (a *AppArgs) Stop() {
+	a.stopOnce.Do(func() {
+		close(a.stopChan())
+	})
+	a.wg.Wait()
+}
+
+// stopChan 停止后台任务的通道，首次使用时创建，以支持未经 New 创建的 AppArgs
+func (a *AppArgs) stopChan() chan struct{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stop == nil {
+		a.stop = make(chan struct{})
+	}
+	return a.stop
+}
+
+func (a *AppArgs) validate(cfg interface{}) error {
+	for _, fn := range a.validators {
+		if err := fn(cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reload 自动重新加载，错误交给 OnReloadError 处理
+func (a *AppArgs) reload() {
+	err := a.Reload()
+	if err == nil {
+		return
+	}
+	if a.onReloadError != nil {
+		a.onReloadError(err)
+		return
+	}
+	output := a.output
+	if output == nil {
+		output = os.Stderr
+	}
+	_, _ = fmt.Fprintf(output, "配置重新加载失败：%v\n", err)
+}
+
+// fileStamp 文件的修改时间及大小，文件不存在时为零值
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchStamps 需要监听的文件当前的状态
+func (a *AppArgs) watchStamps() map[string]fileStamp {
+	files := append([]string{}, a.ConfigFiles()...)
+	stamps := map[string]fileStamp{}
+	for _, filePath := range append(files, a.DotEnvPaths...) {
+		if filePath == "-" {
+			continue
+		}
+		var stamp fileStamp
+		if info, err := os.Stat(filePath); err == nil {
+			stamp = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+		stamps[filePath] = stamp
+	}
+	return stamps
+}
+
+// startWatch 启动配置文件轮询，只启动一次
+func (a *AppArgs) startWatch() {
+	a.mu.Lock()
+	if a.watchInterval <= 0 || a.watching {
+		a.mu.Unlock()
+		return
+	}
+	a.watching = true
+	a.mu.Unlock()
+	last := a.watchStamps()
+	stop := a.stopChan()
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(a.watchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			if reflect.DeepEqual(a.watchStamps(), last) {
+				continue
+			}
+			a.reload()
+			last = a.watchStamps()
+		}
+	}()
+}
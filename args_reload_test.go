@@ -0,0 +1,271 @@
+package args
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "args-watch")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	cfgPath := filepath.Join(dir, "config.yaml")
+	assert.Nil(t, ioutil.WriteFile(cfgPath, []byte("name: v1\ninner:\n  arg: 1\n"), 0644))
+
+	changes := make(chan [2]*TestArg1, 1)
+	reloadErrs := make(chan error, 1)
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=" + cfgPath, "-arg=3"}
+	appArgs := New(args[0], Store(testCfg), Output(ioutil.Discard), FileConfigEnabled("config", "", true, ""),
+		Watch(10*time.Millisecond),
+		Validate(func(cfg interface{}) error {
+			if cfg.(*TestArg1).Name == "bad" {
+				return errors.New("invalid name")
+			}
+			return nil
+		}),
+		OnChange(func(old, new interface{}) {
+			changes <- [2]*TestArg1{old.(*TestArg1), new.(*TestArg1)}
+		}),
+		OnReloadError(func(err error) {
+			reloadErrs <- err
+		}))
+	assert.Nil(t, appArgs.Run(args))
+	defer appArgs.Stop()
+
+	assert.Nil(t, ioutil.WriteFile(cfgPath, []byte("name: v2-changed\ninner:\n  arg: 2\n"), 0644))
+	select {
+	case change := <-changes:
+		assert.Equal(t, "v1", change[0].Name)
+		assert.Equal(t, "v2-changed", change[1].Name)
+		assert.Equal(t, 2, change[1].InnerArg.Arg)
+		// 命令行参数在重新加载时同样生效
+		assert.Equal(t, 3, change[1].Arg)
+	case <-time.After(2 * time.Second):
+		t.Fatal("配置变化未触发重新加载")
+	}
+	// 原对象不被重新加载修改
+	assert.Equal(t, "v1", testCfg.Name)
+
+	assert.Nil(t, ioutil.WriteFile(cfgPath, []byte("name: bad\n"), 0644))
+	select {
+	case err := <-reloadErrs:
+		assert.Equal(t, "invalid name", err.Error())
+	case <-time.After(2 * time.Second):
+		t.Fatal("校验失败未回调 OnReloadError")
+	}
+	assert.Equal(t, "v2-changed", appArgs.Current().(*TestArg1).Name)
+}
+
+func TestReload(t *testing.T) {
+	var mu sync.Mutex
+	env := map[string]string{"INNER_NAME": "env1"}
+	lookup := func(name string) (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		value, found := env[name]
+		return value, found
+	}
+
+	testCfg := &TestArg1{Name: "default", InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test.yaml"}
+	appArgs := New(args[0], Store(testCfg), Output(ioutil.Discard), EnvLookup(lookup), FileConfigEnabled("config", "", true, ""))
+	assert.True(t, errors.Is(appArgs.Reload(), ErrNotRun))
+	assert.Nil(t, appArgs.Run(args))
+	assert.Equal(t, "env1", testCfg.InnerArg.Name)
+
+	mu.Lock()
+	env["INNER_NAME"] = "env2"
+	mu.Unlock()
+	assert.Nil(t, appArgs.Reload())
+	reloaded := appArgs.Current().(*TestArg1)
+	assert.Equal(t, "env2", reloaded.InnerArg.Name)
+	assert.Equal(t, testCfg.Name, reloaded.Name)
+	assert.Equal(t, "env1", testCfg.InnerArg.Name)
+
+	// 重新加载失败时保留原配置
+	mu.Lock()
+	env["INNER_ARG"] = "abc"
+	mu.Unlock()
+	assert.True(t, errors.Is(appArgs.Reload(), ErrArgValue))
+	assert.Same(t, reloaded, appArgs.Current())
+}
+
+func TestOnChangeReload(t *testing.T) {
+	// OnChange 回调中可以再次重新加载
+	var mu sync.Mutex
+	n := 0
+	lookup := func(name string) (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if name == "ARG" {
+			return strconv.Itoa(n), true
+		}
+		return "", false
+	}
+
+	calls := 0
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app"}
+	var appArgs *AppArgs
+	appArgs = New(args[0], Store(testCfg), EnvLookup(lookup), OnChange(func(old, new interface{}) {
+		calls++
+		assert.Nil(t, appArgs.Reload())
+	}))
+	assert.Nil(t, appArgs.Run(args))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		mu.Lock()
+		n = 1
+		mu.Unlock()
+		assert.Nil(t, appArgs.Reload())
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnChange 回调中重新加载时死锁")
+	}
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 1, appArgs.Current().(*TestArg1).Arg)
+}
+
+func TestRunConcurrentWatch(t *testing.T) {
+	// Run 与自动重新加载并发执行
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test.yaml"}
+	appArgs := New(args[0], Store(testCfg), Output(ioutil.Discard), Watch(time.Millisecond),
+		FileConfigEnabled("config", "", true, ""))
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, appArgs.Run(args))
+		}()
+	}
+	wg.Wait()
+	appArgs.Stop()
+}
+
+func TestRunInPlace(t *testing.T) {
+	// Run 写入原有的对象，持有内部对象引用的调用方同样能看到解析结果
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	inner := testCfg.InnerArg
+	args := []string{"test-app", "-config=test_data/test.yaml"}
+	appArgs := New(args[0], Store(testCfg), Output(ioutil.Discard), FileConfigEnabled("config", "", true, ""))
+	assert.Nil(t, appArgs.Run(args))
+	assert.Same(t, inner, testCfg.InnerArg)
+	assert.Equal(t, "test-inner-name", inner.Name)
+
+	// 再次 Run 之前对 CfgData 的修改作为重新加载的默认值
+	testCfg.Ignore = 7
+	assert.Nil(t, appArgs.Run(args))
+	assert.Nil(t, appArgs.Reload())
+	assert.Equal(t, 7, appArgs.Current().(*TestArg1).Ignore)
+}
+
+func TestReloadStdin(t *testing.T) {
+	// 标准输入只能读取一次，重新加载时复用首次读取的内容
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=-", "-config-format=json"}
+	input := strings.NewReader(`{"name": "stdin-name", "inner": {"arg": 7}}`)
+	appArgs := New(args[0], Store(testCfg), Input(input), Output(ioutil.Discard), FileConfigEnabled("config", "", true, ""))
+	assert.Nil(t, appArgs.Run(args))
+	assert.Nil(t, appArgs.Reload())
+	reloaded := appArgs.Current().(*TestArg1)
+	assert.Equal(t, "stdin-name", reloaded.Name)
+	assert.Equal(t, 7, reloaded.InnerArg.Arg)
+}
+
+func TestStopLiteral(t *testing.T) {
+	// 未经 New 创建的 AppArgs 同样可以 Stop
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	appArgs := &AppArgs{Name: "test-app", CfgData: testCfg, watchInterval: time.Millisecond}
+	assert.Nil(t, appArgs.Run([]string{"test-app"}))
+	appArgs.Stop()
+	appArgs.Stop()
+	assert.NotPanics(t, (&AppArgs{}).Stop)
+}
+
+func Test_DeepCopy(t *testing.T) {
+	src := &TestArg1{Name: "a", InnerArg: &TestInnerArg{Array: []string{"x"}, Map: map[string]string{"k": "v"}}}
+	dst := deepCopy(src).(*TestArg1)
+	assert.Equal(t, src, dst)
+	dst.InnerArg.Array[0] = "y"
+	dst.InnerArg.Map["k"] = "w"
+	assert.Equal(t, "x", src.InnerArg.Array[0])
+	assert.Equal(t, "v", src.InnerArg.Map["k"])
+	assert.Nil(t, deepCopy(nil))
+}
+
+func TestCurrent(t *testing.T) {
+	var mu sync.Mutex
+	n := 0
+	lookup := func(name string) (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if name == "ARG" {
+			return strconv.Itoa(n), true
+		}
+		return "", false
+	}
+
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test.yaml"}
+	appArgs := New(args[0], Store(testCfg), Output(ioutil.Discard), EnvLookup(lookup), FileConfigEnabled("config", "", true, ""))
+	assert.Nil(t, appArgs.Current())
+	assert.Nil(t, appArgs.Snapshot())
+	assert.Nil(t, appArgs.Run(args))
+	assert.Equal(t, testCfg, appArgs.Current())
+	assert.False(t, testCfg == appArgs.Current())
+
+	// 重新加载的同时并发读取当前配置
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				cfg := appArgs.Current().(*TestArg1)
+				_ = cfg.Arg + cfg.InnerArg.Arg + len(cfg.InnerArg.Array)
+				snapshot := appArgs.Snapshot().(*TestArg1)
+				snapshot.InnerArg.Name = "changed"
+				snapshot.InnerArg.Array = append(snapshot.InnerArg.Array, "x")
+			}
+		}()
+	}
+	for i := 1; i <= 20; i++ {
+		mu.Lock()
+		n = i
+		mu.Unlock()
+		if i%5 == 0 {
+			// 再次 Run 同样解析到新的对象，不修改已发布的配置
+			assert.Nil(t, appArgs.Run(args))
+			continue
+		}
+		assert.Nil(t, appArgs.Reload())
+	}
+	close(done)
+	wg.Wait()
+
+	cfg := appArgs.Current().(*TestArg1)
+	assert.Equal(t, 20, cfg.Arg)
+	assert.NotEqual(t, "changed", cfg.InnerArg.Name)
+	assert.Equal(t, 20, testCfg.Arg)
+}
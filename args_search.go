@@ -0,0 +1,128 @@
+package args
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SearchMode 配置文件搜索模式
+type SearchMode int
+
+const (
+	// SearchFirst 使用第一个找到的配置文件
+	SearchFirst SearchMode = iota
+	// SearchMerge 合并所有找到的配置文件，靠前的搜索路径优先
+	SearchMerge
+)
+
+// searchConfigFiles 在搜索路径中查找配置文件
+//
+// 搜索路径支持 ~ 及 $VAR 展开，引用的环境变量未设置时跳过该路径；
+// 路径不带支持的扩展名时按注册顺序依次尝试已注册格式的扩展名。
+// 返回的文件按合并顺序排列，即优先级低的在前
+func (a *AppArgs) searchConfigFiles() []string {
+	var found []string
+	for _, searchPath := range a.CfgSearchPaths {
+		searchPath, ok := a.expandSearchPath(searchPath)
+		if !ok {
+			continue
+		}
+
+		candidates := []string{searchPath}
+		if fileUnmarshal(searchPath) == nil {
+			candidates = nil
+			for _, ext := range formatExtensions() {
+				candidates = append(candidates, searchPath+"."+ext)
+			}
+		}
+		for _, candidate := range candidates {
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				if a.CfgSearchMode == SearchFirst {
+					return []string{candidate}
+				}
+				found = append([]string{candidate}, found...)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// expandSearchPath 展开路径中的 ~ 及环境变量
+func (a *AppArgs) expandSearchPath(searchPath string) (string, bool) {
+	if searchPath == "~" || strings.HasPrefix(searchPath, "~/") {
+		home, ok := a.homeDir()
+		if !ok {
+			return "", false
+		}
+		searchPath = path.Join(filepath.ToSlash(home), searchPath[1:])
+	}
+
+	ok := true
+	searchPath = os.Expand(searchPath, func(name string) string {
+		value, found := a.lookupEnv(name)
+		if !found || value == "" {
+			ok = false
+		}
+		return value
+	})
+	return searchPath, ok
+}
+
+// homeDir 用户主目录，优先使用注入的环境变量中的 HOME（Windows 为 USERPROFILE），
+// 未注入环境变量来源时退回 os.UserHomeDir
+func (a *AppArgs) homeDir() (string, bool) {
+	for _, name := range []string{"HOME", "USERPROFILE"} {
+		if home, found := a.lookupEnv(name); found && home != "" {
+			return home, true
+		}
+	}
+	if a.envLookup != nil {
+		return "", false
+	}
+	home, err := os.UserHomeDir()
+	return home, err == nil
+}
+
+// projectConfigFiles 从工作目录开始逐级向上查找项目配置文件，
+// 遇到包含停止标记（如 .git）的目录或到达根目录时停止。
+// 返回的文件按合并顺序排列，即离工作目录远的在前
+func (a *AppArgs) projectConfigFiles() []string {
+	if a.CfgProjectName == "" {
+		return nil
+	}
+	dir := a.WorkDir
+	if dir == "" {
+		var err error
+		if dir, err = os.Getwd(); err != nil {
+			return nil
+		}
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+
+	var found []string
+	for {
+		candidate := filepath.Join(dir, a.CfgProjectName)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			found = append([]string{candidate}, found...)
+			if !a.CfgProjectMerge {
+				return found
+			}
+		}
+		for _, marker := range a.CfgProjectMarkers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return found
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return found
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,111 @@
+package args
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+)
+
+func TestSearchPaths(t *testing.T) {
+	env := EnvMap(map[string]string{"XDG_CONFIG_HOME": "test_data/search/xdg"})
+	paths := []string{"test_data/search/app", "$XDG_CONFIG_HOME/app/config", "$NOT_SET/app/config", "test_data/search/etc/app/config"}
+	args := []string{"test-app"}
+
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	appArgs := New(args[0], Store(testCfg), env, SearchPaths(SearchFirst, paths...))
+	err := appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"test_data/search/xdg/app/config.toml"}, appArgs.ConfigFiles())
+	assert.Equal(t, "xdg-name", testCfg.Name)
+	assert.Equal(t, "", testCfg.InnerArg.Name)
+
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	appArgs = New(args[0], Store(testCfg), env, SearchPaths(SearchMerge, paths...))
+	err = appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"test_data/search/etc/app/config.json", "test_data/search/xdg/app/config.toml"}, appArgs.ConfigFiles())
+	assert.Equal(t, "xdg-name", testCfg.Name)
+	assert.Equal(t, 2, testCfg.Arg)
+	assert.Equal(t, "etc-inner-name", testCfg.InnerArg.Name)
+
+	// 命令行指定的配置文件优先于搜索路径
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	args = []string{"test-app", "-config=test_data/test.yaml"}
+	appArgs = New(args[0], Store(testCfg), env, SearchPaths(SearchFirst, paths...),
+		FileConfigEnabled("config", "test_data/test-default.yaml", true, ""))
+	err = appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"test_data/test.yaml"}, appArgs.ConfigFiles())
+}
+
+func TestSearchPathsUsage(t *testing.T) {
+	output := &bytes.Buffer{}
+	args := []string{"test-app", "-h"}
+	appArgs := New(args[0], Store(&TestArg1{InnerArg: &TestInnerArg{}}), Output(output),
+		SearchPaths(SearchFirst, "test_data/search/etc/app/config"))
+	err := appArgs.Run(args)
+	assert.Equal(t, ErrHelp, err)
+	assert.Contains(t, output.String(), "Config files: test_data/search/etc/app/config.json")
+}
+
+func Test_ExpandSearchPath(t *testing.T) {
+	// ~ 使用注入的环境变量中的 HOME
+	appArgs := New("test-app", EnvMap(map[string]string{"APP": "app", "HOME": "/home/test"}))
+	p, ok := appArgs.expandSearchPath("~/.config/${APP}/config")
+	assert.True(t, ok)
+	assert.Equal(t, "/home/test/.config/app/config", p)
+
+	_, ok = appArgs.expandSearchPath("$NOT_SET/config")
+	assert.False(t, ok)
+
+	// 注入的环境变量中没有 HOME 时不读取进程的主目录
+	appArgs = New("test-app", EnvMap(map[string]string{"APP": "app"}))
+	_, ok = appArgs.expandSearchPath("~/.config/${APP}/config")
+	assert.False(t, ok)
+
+	home, err := os.UserHomeDir()
+	assert.Nil(t, err)
+	appArgs = New("test-app")
+	p, ok = appArgs.expandSearchPath("~/config")
+	assert.True(t, ok)
+	assert.Equal(t, path.Join(filepath.ToSlash(home), "config"), p)
+}
+
+func TestProjectConfig(t *testing.T) {
+	root, err := filepath.Abs("test_data/project")
+	assert.Nil(t, err)
+	args := []string{"test-app"}
+
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	appArgs := New(args[0], Store(testCfg), WorkDir("test_data/project/sub/deep"),
+		ProjectConfig(".toolrc.yaml", true, ".root"))
+	err = appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, ".toolrc.yaml"),
+		filepath.Join(root, "sub", ".toolrc.yaml"),
+	}, appArgs.ConfigFiles())
+	assert.Equal(t, "project-name", testCfg.Name)
+	assert.Equal(t, 2, testCfg.Arg)
+	assert.Equal(t, "project-inner-name", testCfg.InnerArg.Name)
+
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	appArgs = New(args[0], Store(testCfg), WorkDir("test_data/project/sub/deep"),
+		ProjectConfig(".toolrc.yaml", false, ".root"))
+	err = appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "sub", ".toolrc.yaml")}, appArgs.ConfigFiles())
+	assert.Equal(t, "", testCfg.Name)
+	assert.Equal(t, 2, testCfg.Arg)
+
+	// 停止标记所在目录之上的文件不会被加载
+	testCfg = &TestArg1{InnerArg: &TestInnerArg{}}
+	appArgs = New(args[0], Store(testCfg), WorkDir("test_data/project/sub/deep"),
+		ProjectConfig(".toolrc.yaml", true, ".gitkeep"))
+	err = appArgs.Run(args)
+	assert.Nil(t, err)
+	assert.Nil(t, appArgs.ConfigFiles())
+}
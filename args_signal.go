@@ -0,0 +1,44 @@
+package args
+
+import (
+	"os"
+	"os/signal"
+)
+
+// ReloadOnSignal 收到指定信号时重新加载配置，未指定时为 SIGHUP。
+// 重新加载与 Reload 相同，失败时保留原配置并回调 OnReloadError
+func ReloadOnSignal(signals ...os.Signal) Option {
+	return func(args *AppArgs) {
+		if len(signals) == 0 {
+			signals = defaultReloadSignals
+		}
+		args.reloadSignals = signals
+	}
+}
+
+// startSignalReload 注册重新加载信号，只注册一次
+func (a *AppArgs) startSignalReload() {
+	a.mu.Lock()
+	if len(a.reloadSignals) == 0 || a.signaling {
+		a.mu.Unlock()
+		return
+	}
+	a.signaling = true
+	a.mu.Unlock()
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, a.reloadSignals...)
+	stop := a.stopChan()
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ch:
+				a.reload()
+			}
+		}
+	}()
+}
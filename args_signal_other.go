@@ -0,0 +1,8 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package args
+
+import "os"
+
+var defaultReloadSignals []os.Signal
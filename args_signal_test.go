@@ -0,0 +1,81 @@
+//go:build !windows
+// +build !windows
+
+package args
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReloadOnSignal(t *testing.T) {
+	var mu sync.Mutex
+	env := map[string]string{"NAME": "v1"}
+	lookup := func(name string) (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		value, found := env[name]
+		return value, found
+	}
+
+	changes := make(chan interface{}, 1)
+	reloadErrs := make(chan error, 1)
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app"}
+	appArgs := New(args[0], Store(testCfg), Output(ioutil.Discard), EnvLookup(lookup), ReloadOnSignal(),
+		OnChange(func(old, new interface{}) {
+			changes <- new
+		}),
+		OnReloadError(func(err error) {
+			reloadErrs <- err
+		}))
+	assert.Nil(t, appArgs.Run(args))
+	assert.Equal(t, "v1", testCfg.Name)
+
+	mu.Lock()
+	env["NAME"] = "v2"
+	mu.Unlock()
+	assert.Nil(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	select {
+	case cfg := <-changes:
+		assert.Equal(t, "v2", cfg.(*TestArg1).Name)
+	case <-time.After(2 * time.Second):
+		t.Fatal("SIGHUP 未触发重新加载")
+	}
+
+	mu.Lock()
+	env["ARG"] = "abc"
+	mu.Unlock()
+	assert.Nil(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	select {
+	case err := <-reloadErrs:
+		assert.True(t, errors.Is(err, ErrArgValue))
+	case <-time.After(2 * time.Second):
+		t.Fatal("重新加载失败未回调 OnReloadError")
+	}
+	assert.Equal(t, "v2", appArgs.Current().(*TestArg1).Name)
+
+	// Stop 之后不再处理信号，另行接收信号避免进程退出
+	appArgs.Stop()
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	defer signal.Stop(ch)
+	mu.Lock()
+	delete(env, "ARG")
+	env["NAME"] = "v3"
+	mu.Unlock()
+	assert.Nil(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	<-ch
+	select {
+	case <-changes:
+		t.Fatal("Stop 之后仍然重新加载")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,155 @@
+package args
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrUnknownKey = errors.New("配置文件包含未知的键")
+
+// unknownKeys 检查配置树中无法对应到类型 t 中字段的键，字段匹配规则与 assignTree 一致
+func unknownKeys(t reflect.Type, data interface{}, path string) []string {
+	t = realType(t)
+	var keys []string
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fieldType, found := treeField(t, name)
+			if !found {
+				keys = append(keys, joinPath(path, name))
+				continue
+			}
+			keys = append(keys, unknownKeys(fieldType, m[name], joinPath(path, name))...)
+		}
+	case reflect.Map:
+		if m, ok := data.(map[string]interface{}); ok {
+			for name, item := range m {
+				keys = append(keys, unknownKeys(t.Elem(), item, joinPath(path, name))...)
+			}
+			sort.Strings(keys)
+		}
+	case reflect.Slice, reflect.Array:
+		if items, ok := data.([]interface{}); ok {
+			for i, item := range items {
+				keys = append(keys, unknownKeys(t.Elem(), item, joinPath(path, strconv.Itoa(i)))...)
+			}
+		}
+	}
+	return keys
+}
+
+// treeField 查找配置键对应的结构体字段类型
+func treeField(t reflect.Type, key string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, inline, ok := fieldTreeName(field)
+		if !ok {
+			continue
+		}
+		if inline {
+			if fieldType, found := treeField(realType(field.Type), key); found {
+				return fieldType, true
+			}
+			continue
+		}
+		if name == key || strings.EqualFold(name, key) {
+			return field.Type, true
+		}
+	}
+	return nil, false
+}
+
+// keyLine 在配置文件内容中查找键所在行，优先查找完整的点分键（如 properties、TOML 中的 inner.name=x），
+// 其次逐级查找键路径中的各段，找不到时返回 0。
+// 各格式解码后不保留位置信息，这里按常见的键写法查找，结果仅供参考
+func keyLine(content []byte, key string) int {
+	lines := strings.Split(string(content), "\n")
+	if strings.Contains(key, ".") {
+		full := regexp.MustCompile(`^\s*["']?` + regexp.QuoteMeta(key) + `["']?\s*[:=\s]`)
+		for i, line := range lines {
+			if full.MatchString(line) {
+				return i + 1
+			}
+		}
+	}
+	start := -1
+	for _, part := range strings.Split(key, ".") {
+		if _, err := strconv.Atoi(part); err == nil {
+			continue
+		}
+		pattern := regexp.MustCompile(`^\s*(-\s+)?(\[+\s*)?["']?` + regexp.QuoteMeta(part) + `["']?\s*([:=\]{]|\s+\S)|<` + regexp.QuoteMeta(part) + `[\s/>]|\s` + regexp.QuoteMeta(part) + `=["']`)
+		found := -1
+		for i := start + 1; i < len(lines); i++ {
+			if pattern.MatchString(lines[i]) {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			return 0
+		}
+		start = found
+	}
+	return start + 1
+}
+
+// checkUnknownKeys 检查配置文件中的未知键，严格模式下返回错误，否则输出警告。ignored 中的顶层键不做检查
+func (a *AppArgs) checkUnknownKeys(output func(format string, args ...interface{}), filePath string, content []byte, tree map[string]interface{}, ignored map[string]bool) error {
+	t := reflect.TypeOf(a.CfgData)
+	var keys []string
+	if sections, ok := tree[profileSectionKey].(map[string]interface{}); ok && a.CfgProfileArg != "" {
+		// profile 段中的键与顶层的键使用相同的结构
+		top := make(map[string]interface{}, len(tree))
+		for k, v := range tree {
+			if k != profileSectionKey {
+				top[k] = v
+			}
+		}
+		keys = unknownKeys(t, top, "")
+		names := make([]string, 0, len(sections))
+		for name := range sections {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			keys = append(keys, unknownKeys(t, sections[name], joinPath(profileSectionKey, name))...)
+		}
+	} else {
+		keys = unknownKeys(t, tree, "")
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	var errs MultiError
+	for _, key := range keys {
+		if ignored[key] || key == a.CfgIncludeKey || (a.CfgProfileArg != "" && key == profileSectionKey) {
+			continue
+		}
+		line := keyLine(content, key)
+		if !a.CfgStrict {
+			location := filePath
+			if line > 0 {
+				location = fmt.Sprintf("%s:%d", filePath, line)
+			}
+			output("配置文件[%s]未知的键 %s\n", location, key)
+			continue
+		}
+		errs = append(errs, &FileError{Kind: ErrUnknownKey, Path: filePath, Line: line, Cause: errors.New(key)})
+	}
+	return errs.errorOrNil()
+}
@@ -0,0 +1,71 @@
+package args
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func Test_UnknownKeys(t *testing.T) {
+	keys := unknownKeys(reflect.TypeOf(&TestCollectionArg{}), map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{"host": "a", "hots": "b"},
+		},
+		"db": map[string]interface{}{
+			"primary": map[string]interface{}{"PORT": 1, "x": 2},
+		},
+		"other": 1,
+	}, "")
+	assert.Equal(t, []string{"db.primary.x", "other", "servers.0.hots"}, keys)
+}
+
+func Test_KeyLine(t *testing.T) {
+	content := []byte("name: a\ninner:\n  arg: 1\n  name: b\n")
+	assert.Equal(t, 1, keyLine(content, "name"))
+	assert.Equal(t, 4, keyLine(content, "inner.name"))
+	assert.Equal(t, 0, keyLine(content, "inner.age"))
+
+	content = []byte("[inner]\nname = \"a\"\n")
+	assert.Equal(t, 2, keyLine(content, "inner.name"))
+	content = []byte("<config>\n  <inner>\n    <name>a</name>\n  </inner>\n</config>")
+	assert.Equal(t, 3, keyLine(content, "inner.name"))
+	content = []byte("name=a\ninner.arg=1\ninner.name = b\n")
+	assert.Equal(t, 3, keyLine(content, "inner.name"))
+	content = []byte("inner.arg: 1\ninner.name b\n")
+	assert.Equal(t, 2, keyLine(content, "inner.name"))
+}
+
+func TestStrictLine(t *testing.T) {
+	// properties 中的点分键同样给出行号
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test-unknown.properties"}
+	err := New(args[0], Store(testCfg), Output(ioutil.Discard), Strict(), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Equal(t, "配置文件包含未知的键: test_data/test-unknown.properties:3: inner.nmae", err.Error())
+
+	// profile 段中的键按参数结构检查
+	args = []string{"test-app", "-config=test_data/test-unknown-profile.yaml"}
+	err = New(args[0], Store(testCfg), Output(ioutil.Discard), Strict(), Profiles("profile"), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Equal(t, "配置文件包含未知的键: test_data/test-unknown-profile.yaml:6: profiles.prod.inner.nmae", err.Error())
+}
+
+func TestStrict(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test-unknown.yaml"}
+	err := New(args[0], Store(testCfg), Strict(), FileConfigEnabled("config", "", false, "")).Run(args)
+	assert.True(t, errors.Is(err, ErrUnknownKey))
+	assert.Equal(t, "配置文件包含未知的键: test_data/test-unknown.yaml:5: inner.agee; 配置文件包含未知的键: test_data/test-unknown.yaml:2: nmae", err.Error())
+	var fileErr *FileError
+	assert.True(t, errors.As(err, &fileErr))
+	assert.Equal(t, &FileError{Kind: ErrUnknownKey, Path: "test_data/test-unknown.yaml", Line: 5, Cause: errors.New("inner.agee")}, fileErr)
+	assert.Equal(t, "", testCfg.Name)
+
+	output := &bytes.Buffer{}
+	err = New(args[0], Store(testCfg), Output(output), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+	assert.Equal(t, "test-name", testCfg.Name)
+	assert.Contains(t, output.String(), "配置文件[test_data/test-unknown.yaml:2]未知的键 nmae\n")
+	assert.Contains(t, output.String(), "配置文件[test_data/test-unknown.yaml:5]未知的键 inner.agee\n")
+}
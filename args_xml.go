@@ -0,0 +1,110 @@
+package args
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// xmlUnmarshal 解析 XML 格式配置
+//
+// 根元素的子元素及属性映射为顶层键，子元素及属性映射为嵌套键，只有文本的元素解析为字符串，
+// 重复出现的同名元素解析为列表
+func xmlUnmarshal(data []byte, v interface{}) error {
+	tree, err := parseXML(data)
+	if err != nil {
+		return err
+	}
+	return setDecoded(v, tree)
+}
+
+func parseXML(data []byte) (map[string]interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return map[string]interface{}{}, nil
+		}
+		if err != nil {
+			return nil, xmlError(err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			root, err := parseXMLElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			if m, ok := root.(map[string]interface{}); ok {
+				return m, nil
+			}
+			return map[string]interface{}{}, nil
+		}
+	}
+}
+
+// xmlRootAttrs 根元素的属性名，严格模式下不作为未知的键，以便使用 <config version="1"> 这类标注
+func xmlRootAttrs(data []byte) map[string]bool {
+	attrs := map[string]bool{}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return attrs
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			for _, attr := range start.Attr {
+				attrs[attr.Name.Local] = true
+			}
+			return attrs
+		}
+	}
+}
+
+// parseXMLElement 解析 start 开始的元素，返回 map 或字符串
+func parseXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	children := map[string]interface{}{}
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		children[attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, xmlError(err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := parseXMLElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			switch existing := children[name].(type) {
+			case nil:
+				children[name] = child
+			case []interface{}:
+				children[name] = append(existing, child)
+			default:
+				children[name] = []interface{}{existing, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if len(children) == 0 {
+				return strings.TrimSpace(text.String()), nil
+			}
+			return children, nil
+		}
+	}
+}
+
+func xmlError(err error) error {
+	if e, ok := err.(*xml.SyntaxError); ok {
+		return &syntaxError{e.Line, e.Msg}
+	}
+	return err
+}
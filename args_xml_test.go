@@ -0,0 +1,51 @@
+package args
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type TestXMLArg struct {
+	Title string `xml:"title"`
+	Port  int    `xml:"port,attr"`
+}
+
+func Test_ParseXML(t *testing.T) {
+	tree, err := parseXML([]byte(`<root><a>1</a><b x="2"><c>3</c></b><d/><e>4</e><e>5</e></root>`))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"a": "1",
+		"b": map[string]interface{}{"x": "2", "c": "3"},
+		"d": "",
+		"e": []interface{}{"4", "5"},
+	}, tree)
+
+	_, err = parseXML([]byte("<root>\n<a>1</b>\n</root>"))
+	assert.Equal(t, "line 2: element <a> closed by </b>", err.Error())
+}
+
+func Test_ConfigFileXmlFormat(t *testing.T) {
+	testCfg := &TestArg1{InnerArg: &TestInnerArg{}}
+	args := []string{"test-app", "-config=test_data/test.xml"}
+	err := New(args[0], Store(testCfg), FileConfigEnabled("config", "", true, "")).Run(args)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "test-xml-name", testCfg.Name)
+	assert.Equal(t, 88, testCfg.Arg)
+	assert.Equal(t, "test-xml-inner-name", testCfg.InnerArg.Name)
+	assert.Equal(t, 888, testCfg.InnerArg.Arg)
+	assert.Equal(t, []string{"a", "b"}, testCfg.InnerArg.Array)
+}
+
+func Test_XmlTagArgName(t *testing.T) {
+	testCfg := &TestXMLArg{}
+	flags := Bean2Args(testCfg)
+	assert.NotNil(t, flags["title"])
+	assert.NotNil(t, flags["port"])
+
+	err := xmlUnmarshal([]byte(`<server port="8080"><title>xml</title></server>`), testCfg)
+	assert.Nil(t, err)
+	assert.Equal(t, &TestXMLArg{Title: "xml", Port: 8080}, testCfg)
+
+	assert.Equal(t, map[string]bool{"version": true, "port": true}, xmlRootAttrs([]byte(`<?xml version="1.0"?><server version="1" port="8080"/>`)))
+}
@@ -0,0 +1,12 @@
+# HCL config
+name = "test-hcl-name"
+arg  = 77
+
+inner {
+  name  = "test-hcl-inner-name" // comment
+  arg   = 777
+  array = ["a", "b",]
+  map = {
+    k = "v"
+  }
+}